@@ -7,30 +7,9 @@ import (
 	"image"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/project-scale/sim"
 )
 
-// AnimationSkipTicks sets how many ticks to skip before stepping to the next
-// frame in the animation
-const AnimationSkipTicks = 6
-
-// Animate determines the next animation frame for a sprite
-func Animate(frame, tick int, ft FrameTags) int {
-	from, to := ft.From, ft.To
-
-	// Instantly start animation if state changed
-	if frame < from || frame >= to {
-		return from
-	}
-
-	// Update only in every 5th cycle
-	if tick%AnimationSkipTicks != 0 {
-		return frame
-	}
-
-	// Continuously increase the Frame counter between from and to
-	return frame + 1
-}
-
 type SpriteAnimation struct {
 	Sprite   *SpriteSheet
 	FrameTag int
@@ -64,7 +43,7 @@ func (s *SpriteAnimation) Update(frameTag int) (isFirstFrame bool, isLastFrame b
 	}
 
 	// Update only in every 5th cycle
-	if s.Tick%AnimationSkipTicks == 0 {
+	if s.Tick%sim.AnimationSkipTicks == 0 {
 		s.Frame++
 	}
 
@@ -0,0 +1,187 @@
+// Package asset loads the game's images, sprites, maps, sounds and fonts from
+// a file system, reporting what's wrong with them instead of stopping the game
+package asset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"io/fs"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/sinisterstuf/project-scale/sim"
+	"github.com/solarlune/ldtkgo"
+	"github.com/tinne26/etxt"
+)
+
+// Frame is a single frame of an animation, usually a sub-image of a larger
+// image containing several frames
+type Frame struct {
+	Duration int           `json:"duration"`
+	Position FramePosition `json:"frame"`
+}
+
+// FramePosition represents the position of a frame, including the top-left
+// coordinates and its dimensions (width and height)
+type FramePosition struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Frames is a slice of frames used to create sprite animation
+type Frames []Frame
+
+// SpriteMeta contains sprite meta-data, basically everything except frame data
+type SpriteMeta struct {
+	ImageName string         `json:"image"`
+	FrameTags []sim.FrameTag `json:"frameTags"`
+}
+
+// SpriteSheet is the root-node of sprite data, it contains frames and meta data
+// about them
+type SpriteSheet struct {
+	Sprite Frames     `json:"frames"`
+	Meta   SpriteMeta `json:"meta"`
+	Image  *ebiten.Image
+}
+
+// Error is an asset that couldn't be loaded and why
+type Error struct {
+	Name string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Name + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Report keeps track of how loading the assets went
+type Report struct {
+	Loaded   int      // How many assets loaded fine
+	Failures []*Error // Assets that couldn't be loaded
+}
+
+// Add counts an asset as loaded, or as failed if there's an error
+func (r *Report) Add(name string, err error) {
+	if err == nil {
+		r.Loaded++
+		return
+	}
+	// Don't repeat the name if the error is about the file itself
+	if pathErr, ok := err.(*fs.PathError); ok && pathErr.Path == name {
+		err = pathErr.Err
+	}
+	r.Failures = append(r.Failures, &Error{Name: name, Err: err})
+}
+
+// Failed tells whether any asset couldn't be loaded
+func (r *Report) Failed() bool {
+	return len(r.Failures) > 0
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "loaded %d assets, %d failed", r.Loaded, len(r.Failures))
+	for _, f := range r.Failures {
+		b.WriteString("\n" + f.Error())
+	}
+	return b.String()
+}
+
+// Image loads a PNG image
+func Image(fsys fs.FS, name string) (*ebiten.Image, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	raw, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("not a PNG image: %w", err)
+	}
+	return ebiten.NewImageFromImage(raw), nil
+}
+
+// Sprite loads a sprite sheet exported from Aseprite, the frames from the JSON
+// file and the image from the PNG file with the given name
+func Sprite(fsys fs.FS, name string) (*SpriteSheet, error) {
+	ss, err := SpriteFrames(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	ss.Image, err = Image(fsys, name+".png")
+	if err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// SpriteFrames loads the frames of a sprite sheet from its JSON file, leaving
+// out the image
+func SpriteFrames(fsys fs.FS, name string) (*SpriteSheet, error) {
+	data, err := fs.ReadFile(fsys, name+".json")
+	if err != nil {
+		return nil, err
+	}
+
+	var ss SpriteSheet
+	if err := json.Unmarshal(data, &ss); err != nil {
+		return nil, fmt.Errorf("not a sprite sheet: %w", err)
+	}
+	if len(ss.Sprite) == 0 {
+		return nil, errors.New("the sprite sheet has no frames")
+	}
+	return &ss, nil
+}
+
+// Project loads an LDtk project
+func Project(fsys fs.FS, name string) (*ldtkgo.Project, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	project, err := sim.ReadProject(file)
+	if err != nil {
+		return nil, fmt.Errorf("not an LDtk project: %w", err)
+	}
+	return project, nil
+}
+
+// Sound loads an OGG Vorbis sound file, checking its headers, the sound itself
+// is only decoded as it plays
+func Sound(fsys fs.FS, name string) ([]byte, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := vorbis.DecodeWithoutResampling(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("not an OGG Vorbis sound: %w", err)
+	}
+	return data, nil
+}
+
+// Font loads a TrueType or OpenType font, it returns the font's name as well
+func Font(fsys fs.FS, name string) (*etxt.Font, string, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, "", err
+	}
+	font, fontName, err := etxt.ParseFontBytes(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("not a font: %w", err)
+	}
+	return font, fontName, nil
+}
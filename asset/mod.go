@@ -0,0 +1,99 @@
+package asset
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ModManifest is the file in a mod's folder that describes it
+const ModManifest = "mod.json"
+
+// Mod is a folder of assets that replace the game's own, laid out like the
+// game's assets folder is in the repository, e.g. assets/sprites/Nanobot.png
+type Mod struct {
+	Name        string `json:"name"`
+	Author      string `json:"author"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Order       int    `json:"order"` // Mods later in the load order replace the assets of earlier ones
+	Dir         string `json:"-"`     // Folder the mod is in
+}
+
+// FindMods reads the manifests of the mods in the subfolders of a folder, in
+// load order, folders without a manifest or with a broken one are skipped
+func FindMods(dir string) ([]*Mod, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var mods []*Mod
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		modDir := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(modDir, ModManifest))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			log.Println("Skipping mod:", err)
+			continue
+		}
+		mod := &Mod{Name: entry.Name()}
+		if err := json.Unmarshal(data, mod); err != nil {
+			log.Printf("Skipping mod %s: %v\n", entry.Name(), err)
+			continue
+		}
+		mod.Dir = modDir
+		mods = append(mods, mod)
+	}
+
+	slices.SortStableFunc(mods, func(a, b *Mod) int {
+		return cmp.Or(cmp.Compare(a.Order, b.Order), cmp.Compare(a.Dir, b.Dir))
+	})
+	return mods, nil
+}
+
+// Layer is one of the file systems of a layered file system
+type Layer struct {
+	Name string // Where its files come from, like the name of a mod
+	FS   fs.FS
+}
+
+// Layered is a file system made of several, files in later layers replace the
+// same files in earlier ones
+type Layered []Layer
+
+// Open opens the file from the last layer that has it
+func (l Layered) Open(name string) (fs.File, error) {
+	for i := len(l) - 1; i >= 0; i-- {
+		file, err := l[i].FS.Open(name)
+		if err == nil {
+			return file, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %w", l[i].Name, err)
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Origin is the name of the layer a file is opened from, or that failed to
+// open it like Open does, false if there's no such file
+func (l Layered) Origin(name string) (string, bool) {
+	for i := len(l) - 1; i >= 0; i-- {
+		if _, err := fs.Stat(l[i].FS, name); !errors.Is(err, fs.ErrNotExist) {
+			return l[i].Name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,119 @@
+package asset
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// brokenFS fails to open anything for a reason other than it not being there
+type brokenFS struct{}
+
+func (brokenFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func TestLayered(t *testing.T) {
+	game := fstest.MapFS{
+		"assets/a.png": {Data: []byte("game a")},
+		"assets/b.png": {Data: []byte("game b")},
+		"assets/c.png": {Data: []byte("game c")},
+	}
+	first := fstest.MapFS{
+		"assets/b.png": {Data: []byte("first b")},
+		"assets/c.png": {Data: []byte("first c")},
+	}
+	second := fstest.MapFS{
+		"assets/c.png": {Data: []byte("second c")},
+		"assets/d.png": {Data: []byte("second d")},
+	}
+	layered := Layered{{"game", game}, {"first", first}, {"second", second}}
+
+	for _, tc := range []struct {
+		name   string
+		layers Layered
+		file   string
+		want   string // Contents of the file, empty if it can't be opened
+		origin string // Layer it's opened from or fails in, empty if it's nowhere
+	}{
+		{"only the game's", layered, "assets/a.png", "game a", "game"},
+		{"replaced by a mod", layered, "assets/b.png", "first b", "first"},
+		{"replaced by the last mod", layered, "assets/c.png", "second c", "second"},
+		{"added by a mod", layered, "assets/d.png", "second d", "second"},
+		{"nowhere", layered, "assets/e.png", "", ""},
+		{"broken mod over the game's", Layered{{"game", game}, {"broken", brokenFS{}}}, "assets/a.png", "", "broken"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := tc.layers.Open(tc.file)
+			var got string
+			if err == nil {
+				data, _ := io.ReadAll(file)
+				file.Close()
+				got = string(data)
+			}
+			if got != tc.want {
+				t.Errorf("opened %q, want %q: %v", got, tc.want, err)
+			}
+			if origin, ok := tc.layers.Origin(tc.file); origin != tc.origin || ok != (tc.origin != "") {
+				t.Errorf("file comes from %q, want %q", origin, tc.origin)
+			}
+		})
+	}
+
+	// A missing file is reported like any other file system would, a mod
+	// that can't be read says which mod it is
+	if _, err := layered.Open("assets/e.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("opening a missing file: %v, want it not to exist", err)
+	}
+	broken := Layered{{"game", game}, {"broken", brokenFS{}}}
+	if _, err := broken.Open("assets/a.png"); !errors.Is(err, fs.ErrPermission) || !strings.HasPrefix(err.Error(), "broken: ") {
+		t.Errorf("opening a file in a broken mod: %v, want the mod's error", err)
+	}
+}
+
+func TestFindMods(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		t.Helper()
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("late/mod.json", `{"name": "Late", "order": 2}`)
+	write("early/mod.json", `{"name": "Early", "order": 1}`)
+	write("b/mod.json", `{"order": 1}`)
+	write("broken/mod.json", `{"name": `)
+	write("plain/assets/icon.png", "not a mod")
+	write("loose.json", `{"name": "Not in a folder"}`)
+
+	mods, err := FindMods(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, mod := range mods {
+		names = append(names, mod.Name)
+	}
+	// Same order by folder, mods without a name are named after it
+	want := []string{"b", "Early", "Late"}
+	if !slices.Equal(names, want) {
+		t.Errorf("found mods %v, want %v in load order", names, want)
+	}
+	if len(mods) > 0 && mods[0].Dir != filepath.Join(dir, "b") {
+		t.Errorf("mod b is in %s, want %s", mods[0].Dir, filepath.Join(dir, "b"))
+	}
+
+	if mods, err := FindMods(filepath.Join(dir, "missing")); mods != nil || err != nil {
+		t.Errorf("found mods %v in a missing folder: %v, want none", mods, err)
+	}
+}
@@ -0,0 +1,120 @@
+// lint-map checks LDtk maps for mistakes that would stop a level from loading
+// or being finished, so they're found before the game is shipped instead of
+// when it crashes at startup.
+//
+// Usage:
+//
+//	go run ./cmd/lint-map [file.ldtk ...]
+//
+// With no files it checks the game's own map. It exits with status 1 if any
+// level has problems, including levels missing their layers, Player_start or
+// Finish. With -skip-drafts, levels with neither a Player_start nor a Finish
+// are taken for drafts the game leaves out and aren't checked.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sinisterstuf/project-scale/sim"
+	"github.com/solarlune/ldtkgo"
+)
+
+const defaultMap = "assets/maps/Project scale.ldtk"
+
+var (
+	skipDrafts bool // Leaves levels without a start and finish unchecked
+	spriteFile string
+	showRoute  bool
+	frameTags  []sim.FrameTag
+	difficulty *sim.Difficulty
+)
+
+func main() {
+	log.SetFlags(0)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [file.ldtk ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.BoolVar(&skipDrafts, "skip-drafts", false, "don't check levels without a start and finish, the game leaves them out")
+	flag.StringVar(&spriteFile, "sprite", "assets/sprites/Nanobot.json", "time routes using the animations in sprite `file`")
+	flag.BoolVar(&showRoute, "route", false, "print the quickest route to the finish of every level")
+	difficultyName := flag.String("difficulty", sim.DifficultyNormal.Name, "find routes moving as fast and far as difficulty `name` allows")
+	flag.Parse()
+
+	if difficulty = sim.DifficultyByName(*difficultyName); difficulty == nil {
+		log.Fatalln("Unknown difficulty:", *difficultyName)
+	}
+
+	if data, err := os.ReadFile(spriteFile); err != nil {
+		log.Println("Timing routes without animations:", err)
+	} else if frameTags, err = sim.ParseFrameTags(data); err != nil {
+		log.Println("Timing routes without animations:", err)
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{defaultMap}
+	}
+
+	failed := false
+	for _, name := range files {
+		if !lint(name) {
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// lint prints the problems of every level in an LDtk file and reports whether
+// there were none
+func lint(name string) bool {
+	file, err := os.Open(name)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	defer file.Close()
+
+	project, err := sim.ReadProject(file)
+	if err != nil {
+		log.Printf("%s: error parsing LDtk project: %v\n", name, err)
+		return false
+	}
+
+	ok := true
+	for _, level := range project.Levels {
+		if skipDrafts && isDraft(level) {
+			fmt.Printf("%s: level %s is a draft without %s and %s, skipped\n", name, level.Identifier, sim.EntityPlayerStart, sim.EntityFinish)
+			continue
+		}
+		route, problems := sim.LintLevel(level, frameTags, difficulty)
+		for _, problem := range problems {
+			log.Printf("%s: %v\n", name, problem)
+		}
+		if len(problems) > 0 {
+			ok = false
+			continue
+		}
+		fmt.Printf("%s: level %s OK, finish reached in %.1fs at best\n", name, level.Identifier, float64(route.Ticks)/60)
+		if showRoute {
+			for _, step := range route.Steps {
+				fmt.Printf("\t%6.2fs %-8s to tile %d, %d with %3.0f%% grip\n", float64(step.Ticks)/60, sim.MoveNames[step.Move], step.X, step.Y, step.Grip/sim.MaxGrip*100)
+			}
+		}
+	}
+	return ok
+}
+
+// isDraft reports whether a level has neither a start nor a finish yet, so the
+// game leaves it out of the campaign
+func isDraft(level *ldtkgo.Level) bool {
+	entities := level.LayerByIdentifier(sim.LayerEntities)
+	return entities == nil ||
+		entities.EntityByIdentifier(sim.EntityPlayerStart) == nil &&
+			entities.EntityByIdentifier(sim.EntityFinish) == nil
+}
@@ -25,6 +25,14 @@ type ControlHint struct {
 	Alpha    float64
 }
 
+// NewControlHints creates the hints teaching the controls on the first level
+func NewControlHints() []*ControlHint {
+	hints := make([]*ControlHint, 2)
+	hints[0] = &ControlHint{Sprite: NewSpriteAnimation("Controls"), FrameTag: 0, From: 3232, To: 3120, Dx: -8, Dy: -8}
+	hints[1] = &ControlHint{Sprite: NewSpriteAnimation("Controls"), FrameTag: 1, From: 155 * 16, To: 148 * 16, Dx: -8, Dy: 8}
+	return hints
+}
+
 func (c *ControlHint) Update(y float64) error {
 	if c.State == hintHidden {
 		if y <= c.From {
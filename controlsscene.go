@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joelschutz/stagehand"
+	input "github.com/quasilyte/ebitengine-input"
+	"github.com/tinne26/etxt"
+)
+
+// How long to wait for a key to be pressed when binding one
+const captureTime = 5 * 60
+
+// ControlsScene lists the keys of each action and lets the player bind a
+// different key or gamepad button to them
+type ControlsScene struct {
+	BaseScene
+	Menu      *Menu
+	Scanner   *input.KeyScanner
+	Gamepad   *input.Handler // Maps each gamepad key to its own action to find out which one is pressed
+	Capturing int            // Action waiting for a key, -1 when not binding
+	Timeout   int            // Ticks left to press a key
+	Message   string
+}
+
+func NewControlsScene(game *Game) *ControlsScene {
+	gamepad := input.Keymap{}
+	for i, k := range gamepadKeys {
+		gamepad[input.Action(i)] = []input.Key{k}
+	}
+
+	return &ControlsScene{
+		Scanner:   input.NewKeyScanner(game.Input),
+		Gamepad:   game.InputSystem.NewHandler(0, gamepad),
+		Capturing: -1,
+		Menu: &Menu{
+			X:             gameWidth / 2,
+			Y:             50,
+			color:         color.RGBA{255, 255, 255, 255},
+			selectedColor: color.RGBA{255, 255, 0, 255},
+			textRenderer:  game.TextRenderer,
+			sound:         game.MenuSound,
+			Input:         game.Input,
+		},
+	}
+}
+
+func (s *ControlsScene) Update() error {
+	s.State.InputSystem.Update()
+
+	if s.Capturing >= 0 {
+		s.capture()
+		return nil
+	}
+
+	s.Menu.Update()
+	if s.State.Input.ActionIsJustPressed(ActionMoveUp) || s.State.Input.ActionIsJustPressed(ActionMoveDown) {
+		s.Message = ""
+	}
+
+	if s.State.Input.ActionIsJustPressed(ActionMenu) {
+		s.SceneManager.SwitchTo(s.State.Scenes[gameOptions])
+		return nil
+	}
+
+	if s.State.Input.ActionIsJustPressed(ActionPrimary) {
+		switch s.Menu.Active {
+		case len(actionNames):
+			s.State.Keymap = DefaultKeymap()
+			s.State.Input.Remap(s.State.Keymap)
+			SaveKeymap(s.State.Keymap)
+			s.Message = "Controls reset to defaults"
+			s.updateItems()
+		case len(actionNames) + 1:
+			s.SceneManager.SwitchTo(s.State.Scenes[gameOptions])
+			return nil
+		default:
+			s.Capturing = s.Menu.Active
+			s.Timeout = captureTime
+		}
+	}
+
+	s.State.Fog.Update()
+
+	return nil
+}
+
+// capture waits for a key or gamepad button to be pressed for the action
+// being bound
+func (s *ControlsScene) capture() {
+	s.Timeout--
+	if s.Timeout <= 0 {
+		s.Capturing = -1
+		s.Message = ""
+		return
+	}
+
+	if k, status := s.Scanner.Scan(); status == input.KeyScanCompleted {
+		s.bind(k)
+		return
+	}
+	for i, k := range gamepadKeys {
+		if s.Gamepad.ActionIsJustPressed(input.Action(i)) {
+			s.bind(k)
+			return
+		}
+	}
+}
+
+// bind replaces the keys of the same device bound to the action being
+// captured with the new key, unless it's used for another action already
+func (s *ControlsScene) bind(k input.Key) {
+	action := input.Action(s.Capturing)
+	s.Capturing = -1
+
+	for other, keys := range s.State.Keymap {
+		for _, bound := range keys {
+			if bound == k && other != action {
+				s.Message = fmt.Sprintf("%s is already used for %s", keyName(k), actionNames[other])
+				return
+			}
+		}
+	}
+
+	keys := []input.Key{k}
+	for _, bound := range s.State.Keymap[action] {
+		if isGamepadKey(bound) != isGamepadKey(k) {
+			keys = append(keys, bound)
+		}
+	}
+	s.State.Keymap[action] = keys
+	s.State.Input.Remap(s.State.Keymap)
+	SaveKeymap(s.State.Keymap)
+	s.Message = ""
+	s.updateItems()
+}
+
+// keyName is how a key is shown to the player
+func keyName(k input.Key) string {
+	return strings.TrimPrefix(k.String(), "gamepad_")
+}
+
+// keyNames lists the names of the keys bound to an action from one device
+func (s *ControlsScene) keyNames(action input.Action, gamepad bool) string {
+	var names []string
+	for _, k := range s.State.Keymap[action] {
+		if isGamepadKey(k) == gamepad {
+			names = append(names, keyName(k))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// updateItems shows the keyboard keys bound to each action in the menu
+func (s *ControlsScene) updateItems() {
+	s.Menu.Items = s.Menu.Items[:0]
+	for action, name := range actionNames {
+		s.Menu.Items = append(s.Menu.Items, name+": "+s.keyNames(input.Action(action), false))
+	}
+	s.Menu.Items = append(s.Menu.Items, "Reset to defaults", "Back")
+}
+
+func (s *ControlsScene) Draw(screen *ebiten.Image) {
+	drawOptionsBackground(s.State, screen)
+
+	s.State.TextRenderer.Draw(screen, "Controls", color.White, 8, 50, 10)
+	s.Menu.Draw(screen)
+
+	// Details of the selected action
+	details := s.Message
+	if s.Capturing >= 0 {
+		details = fmt.Sprintf("Press a key or button for %s... %d", actionNames[s.Capturing], s.Timeout/60+1)
+	} else if details == "" && s.Menu.Active < len(actionNames) {
+		details = "Gamepad: " + s.keyNames(input.Action(s.Menu.Active), true)
+	}
+	s.State.TextRenderer.DrawXY(screen, details, color.RGBA{255, 255, 0, 255}, 8, gameWidth/2, 180, etxt.XCenter)
+}
+
+func (s *ControlsScene) Load(st State, sm *stagehand.SceneManager[State]) {
+	s.BaseScene.Load(st, sm)
+	s.Menu.Active = 0
+	s.Capturing = -1
+	s.Message = ""
+	s.updateItems()
+}
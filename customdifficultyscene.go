@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joelschutz/stagehand"
+	"github.com/sinisterstuf/project-scale/sim"
+)
+
+// customSetting is a number of the custom difficulty that left and right
+// change in steps, between the smallest and largest it can be
+type customSetting struct {
+	Name           string
+	Field          func(d *sim.Difficulty) *float64
+	Step, Min, Max float64
+	Format         string // How the value is shown, with fmt verbs
+	Zero           string // Shown instead of the value when it's 0, if set
+}
+
+// customSettings are the numbers of the custom difficulty in the order they're
+// listed, the water's bands can still only be changed in the saved game data
+var customSettings = []customSetting{
+	{"Water speed", func(d *sim.Difficulty) *float64 { return &d.WaterSpeed }, 0.05, 0, 2, "%.2f", ""},
+	{"Water speed-up", func(d *sim.Difficulty) *float64 { return &d.WaterAccel }, 0.05, 0, 1, "%.2f", "none"},
+	{"Water top speed", func(d *sim.Difficulty) *float64 { return &d.WaterMaxSpeed }, 0.05, 0, 2, "%.2f", "none"},
+	{"Climb speed", func(d *sim.Difficulty) *float64 { return &d.ClimbSpeed }, 0.1, 0.2, 4, "%.1f", ""},
+	{"Jump speed", func(d *sim.Difficulty) *float64 { return &d.JumpSpeed }, 0.5, 1, 8, "%.1f", ""},
+	{"Shortest jump", func(d *sim.Difficulty) *float64 { return &d.MinJumpDist }, 4, 4, 8 * gridSize, "%.0f px", ""},
+	{"Longest jump", func(d *sim.Difficulty) *float64 { return &d.MaxJumpDist }, 4, 4, 8 * gridSize, "%.0f px", ""},
+}
+
+// CustomDifficultyScene is where the player sets up the custom difficulty,
+// left and right change the selected number and every change is saved
+type CustomDifficultyScene struct {
+	BaseScene
+	Menu       *Menu
+	Difficulty sim.Difficulty
+}
+
+func NewCustomDifficultyScene(game *Game) *CustomDifficultyScene {
+	return &CustomDifficultyScene{
+		Menu: &Menu{
+			X:             gameWidth / 2,
+			Y:             50,
+			color:         color.RGBA{255, 255, 255, 255},
+			selectedColor: color.RGBA{255, 255, 0, 255},
+			textRenderer:  game.TextRenderer,
+			sound:         game.MenuSound,
+			Input:         game.Input,
+		},
+	}
+}
+
+func (s *CustomDifficultyScene) Update() error {
+	s.State.InputSystem.Update()
+	s.Menu.Update()
+
+	reset, back := len(customSettings), len(customSettings)+1
+	if s.State.Input.ActionIsJustPressed(ActionMenu) ||
+		s.State.Input.ActionIsJustPressed(ActionPrimary) && s.Menu.Active == back {
+		s.SceneManager.SwitchTo(s.State.Scenes[gameOptions])
+		return nil
+	}
+	if s.State.Input.ActionIsJustPressed(ActionPrimary) && s.Menu.Active == reset {
+		s.Difficulty = sim.DifficultyNormal
+		s.Difficulty.Name = customDifficulty
+		s.save()
+	}
+
+	if s.Menu.Active < len(customSettings) {
+		if s.State.Input.ActionIsJustPressed(ActionMoveLeft) {
+			s.change(-1)
+		}
+		if s.State.Input.ActionIsJustPressed(ActionMoveRight) {
+			s.change(1)
+		}
+	}
+
+	s.State.Fog.Update()
+
+	return nil
+}
+
+// change steps the selected number up or down, keeping the shortest jump no
+// longer than the longest
+func (s *CustomDifficultyScene) change(step int) {
+	setting := customSettings[s.Menu.Active]
+	value := setting.Field(&s.Difficulty)
+	*value = math.Round((*value+float64(step)*setting.Step)/setting.Step) * setting.Step
+	*value = math.Min(math.Max(*value, setting.Min), setting.Max)
+
+	if s.Difficulty.MinJumpDist > s.Difficulty.MaxJumpDist {
+		if value == &s.Difficulty.MinJumpDist {
+			s.Difficulty.MaxJumpDist = s.Difficulty.MinJumpDist
+		} else {
+			s.Difficulty.MinJumpDist = s.Difficulty.MaxJumpDist
+		}
+	}
+	s.save()
+}
+
+// save keeps the custom difficulty and plays the next round on it if it's
+// the one chosen
+func (s *CustomDifficultyScene) save() {
+	saveCustomDifficulty(&s.Difficulty)
+	if s.State.Difficulty.Name == customDifficulty {
+		custom := s.Difficulty
+		s.State.Difficulty = &custom
+	}
+	s.updateItems()
+}
+
+// updateItems shows the current value of each number in the menu
+func (s *CustomDifficultyScene) updateItems() {
+	s.Menu.Items = s.Menu.Items[:0]
+	for _, setting := range customSettings {
+		value := *setting.Field(&s.Difficulty)
+		text := fmt.Sprintf(setting.Format, value)
+		if value == 0 && setting.Zero != "" {
+			text = setting.Zero
+		}
+		s.Menu.Items = append(s.Menu.Items, setting.Name+": "+text)
+	}
+	s.Menu.Items = append(s.Menu.Items, "Reset to Normal", "Back")
+}
+
+func (s *CustomDifficultyScene) Draw(screen *ebiten.Image) {
+	drawOptionsBackground(s.State, screen)
+
+	s.State.TextRenderer.Draw(screen, "Custom difficulty", color.White, 8, 50, 10)
+	s.Menu.Draw(screen)
+
+	info := "Left and right change the selected number"
+	if s.State.Difficulty.Name != customDifficulty {
+		info += ",\nchoose Custom on the start screen to play it"
+	}
+	s.State.TextRenderer.Draw(screen, info, color.White, 8, 20, 180)
+}
+
+func (s *CustomDifficultyScene) Load(st State, sm *stagehand.SceneManager[State]) {
+	s.BaseScene.Load(st, sm)
+	s.Difficulty = *loadCustomDifficulty()
+	s.Menu.Active = 0
+	s.updateItems()
+}
@@ -4,10 +4,19 @@
 package main
 
 import (
+	"log"
+	"strings"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-var debuggers Debuggers
+// debugOverlays are the debuggers that can be turned on with the -debug flag
+// by name, debug builds add them
+var debugOverlays = map[string]Debugger{}
+
+// defaultDebugOverlays are the names of the debuggers shown without the -debug
+// flag
+var defaultDebugOverlays string
 
 // Debugger provides debug information by rendering it on-screen
 type Debugger interface {
@@ -36,3 +45,20 @@ func (ds Debuggers) Debug(g *GameScene, screen *ebiten.Image) {
 func (ds *Debuggers) Add(d Debugger) {
 	*ds = append(*ds, d)
 }
+
+// NewDebuggers turns on the debug overlays with the given comma-separated names
+func NewDebuggers(names string) Debuggers {
+	var ds Debuggers
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if d, ok := debugOverlays[name]; ok {
+			ds.Add(d)
+		} else {
+			log.Println("There's no debug overlay called", name, "in this build")
+		}
+	}
+	return ds
+}
@@ -1,7 +1,7 @@
 // Use of this source code is subject to an MIT-style
 // licence which can be found in the LICENSE file.
 
-//go:build !release && debugcol
+//go:build !release
 
 package main
 
@@ -10,11 +10,12 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/sinisterstuf/project-scale/sim"
 	"github.com/solarlune/resolv"
 )
 
 func init() {
-	debuggers.Add(DebugFunc(DebugCollision))
+	debugOverlays["collision"] = DebugFunc(DebugCollision)
 }
 
 // DebugCollision draws boxes around objects in collision space to easily
@@ -38,11 +39,11 @@ func debugPosition(g *GameScene, screen *ebiten.Image, o *resolv.Object) {
 	lineColor := color.NRGBA{255, 255, 255, 255}
 	if tags := o.Tags(); len(tags) > 0 {
 		switch tags[0] {
-		case TagWall:
+		case sim.TagWall:
 			lineColor = color.NRGBA{255, 0, 0, 255}
-		case TagChasm:
+		case sim.TagChasm:
 			lineColor = color.NRGBA{0, 255, 0, 255}
-		case TagSlippery:
+		case sim.TagSlippery:
 			lineColor = color.NRGBA{0, 0, 255, 255}
 		}
 	}
@@ -0,0 +1,58 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+//go:build !release
+
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/sinisterstuf/project-scale/sim"
+)
+
+func init() {
+	debugOverlays["route"] = DebugFunc(DebugRoute)
+}
+
+// The route is only worked out again when the level changes
+var (
+	routeWorld *sim.World
+	route      *sim.Route
+)
+
+// routeColors are the colours of the line for each kind of move on the route
+var routeColors = []color.NRGBA{
+	sim.MoveStart:   {255, 255, 255, 255},
+	sim.MoveClimb:   {255, 255, 255, 255},
+	sim.MoveWalk:    {255, 255, 255, 255},
+	sim.MoveJump:    {255, 255, 0, 255},
+	sim.MoveFall:    {0, 255, 0, 255},
+	sim.MoveSlip:    {0, 0, 255, 255},
+	sim.MoveGrapple: {255, 0, 255, 255},
+}
+
+// DebugRoute draws the quickest route from the start to the finish that the
+// solver can find, coloured by how each tile is reached
+func DebugRoute(g *GameScene, screen *ebiten.Image) {
+	if g.State.World != routeWorld {
+		routeWorld = g.State.World
+		route, _ = routeWorld.Solve()
+	}
+	if route == nil {
+		return
+	}
+
+	for i := 1; i < len(route.Steps); i++ {
+		from, to := route.Steps[i-1], route.Steps[i]
+		fX, fY := g.State.Camera.GetScreenCoords(
+			float64(from.X*gridSize+gridSize/2), float64(from.Y*gridSize+gridSize/2),
+		)
+		tX, tY := g.State.Camera.GetScreenCoords(
+			float64(to.X*gridSize+gridSize/2), float64(to.Y*gridSize+gridSize/2),
+		)
+		ebitenutil.DrawLine(screen, fX, fY, tX, tY, routeColors[to.Move])
+	}
+}
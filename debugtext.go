@@ -10,10 +10,12 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/sinisterstuf/project-scale/sim"
 )
 
 func init() {
-	debuggers.Add(DebugFunc(DebugText))
+	debugOverlays["text"] = DebugFunc(DebugText)
+	defaultDebugOverlays = "text"
 
 	// Uncap FPS so you can see if a code change has had an impact on
 	// the game's performance
@@ -36,7 +38,7 @@ func DebugText(g *GameScene, screen *ebiten.Image) {
 		player.Position.X/gridSize,
 		player.Position.Y/gridSize,
 		player.WhatTiles,
-		playerStateNames[player.State],
-		playerAnimationNames[player.AnimState],
+		sim.PlayerStateNames[player.State],
+		sim.PlayerAnimationNames[player.AnimState],
 	))
 }
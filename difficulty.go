@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/sinisterstuf/project-scale/sim"
+)
+
+// customDifficulty is the name of the difficulty the player sets up in the
+// options, it starts out the same as normal
+const customDifficulty = "Custom"
+
+// difficultyNames are the difficulties offered on the start screen in order
+func difficultyNames() []string {
+	var names []string
+	for _, d := range sim.Difficulties {
+		names = append(names, d.Name)
+	}
+	return append(names, customDifficulty)
+}
+
+// nextDifficulty is the difficulty after the named one on the start screen
+func nextDifficulty(name string) string {
+	names := difficultyNames()
+	for i, n := range names {
+		if n == name {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return sim.DifficultyNormal.Name
+}
+
+// loadDifficulty looks up a difficulty by name, anything unknown is normal
+func loadDifficulty(name string) *sim.Difficulty {
+	if name == customDifficulty {
+		return loadCustomDifficulty()
+	}
+	if d := sim.DifficultyByName(name); d != nil {
+		return d
+	}
+	return &sim.DifficultyNormal
+}
+
+// loadCustomDifficulty reads the custom difficulty from the saved game data,
+// saving a copy of normal there the first time so that there's something to
+// edit
+func loadCustomDifficulty() *sim.Difficulty {
+	d := sim.DifficultyNormal
+	d.Name = customDifficulty
+	m, err := openGameData()
+	if err != nil {
+		return &d
+	}
+
+	result, err := m.LoadItem("Difficulty.Custom")
+	if err != nil {
+		saveCustomDifficulty(&d)
+		return &d
+	}
+
+	custom := d
+	if err := json.Unmarshal(result, &custom); err != nil {
+		log.Println("Playing custom difficulty as normal:", err)
+		return &d
+	}
+	if err := custom.Validate(); err != nil {
+		log.Println("Playing custom difficulty as normal:", err)
+		return &d
+	}
+	custom.Name = customDifficulty
+	return &custom
+}
+
+// saveCustomDifficulty writes the custom difficulty to the saved game data
+func saveCustomDifficulty(d *sim.Difficulty) {
+	m, err := openGameData()
+	if err != nil {
+		return
+	}
+	data, _ := json.MarshalIndent(d, "", "\t")
+	if err := m.SaveItem("Difficulty.Custom", data); err != nil {
+		log.Println("Error saving custom difficulty:", err)
+	}
+}
+
+// difficultyMenuItem is the label of the menu item that changes the difficulty
+func difficultyMenuItem(name string) string {
+	return "Difficulty: " + name
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/sinisterstuf/project-scale/asset"
+)
+
+// Size of the debug font the error scene is written in, it's built into
+// Ebitengine so it works even if the game's fonts are what's broken
+const debugCharWidth, debugLineHeight = 6, 16
+
+// ErrorScene is shown instead of the game when some of its assets couldn't be
+// loaded, it lists them so that a broken install can be fixed
+type ErrorScene struct {
+	Width    int
+	Height   int
+	Failures []*asset.Error
+}
+
+func NewErrorScene(report *asset.Report) *ErrorScene {
+	return &ErrorScene{
+		Width:    gameWidth,
+		Height:   gameHeight,
+		Failures: report.Failures,
+	}
+}
+
+func (s *ErrorScene) Layout(w, h int) (int, int) {
+	return s.Width, s.Height
+}
+
+// Update quits the game when Esc or Enter is pressed
+func (s *ErrorScene) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		return ebiten.Termination
+	}
+	return nil
+}
+
+// Draw lists the assets that failed to load, as many as fit on the screen
+func (s *ErrorScene) Draw(screen *ebiten.Image) {
+	const margin = 4
+	columns := (s.Width - margin*2) / debugCharWidth
+	rows := (s.Height-margin*2)/debugLineHeight - 6 // Leave room for the header and footer
+
+	var lines []string
+	for _, f := range s.Failures {
+		name := f.Name
+		if origin, ok := assetFS.Origin(name); ok && origin != gameLayer {
+			name += " from " + origin
+		}
+		lines = append(lines, wrapText(name, columns)...)
+		lines = append(lines, wrapText("  "+f.Err.Error(), columns)...)
+	}
+	if len(lines) > rows {
+		lines = append(lines[:rows-1], fmt.Sprintf("...and more, %d files in all", len(s.Failures)))
+	}
+
+	fix := "Reinstalling the game should fix it."
+	if len(loadedMods) > 0 {
+		fix = fmt.Sprintf("Taking the mods out of the %s folder or\nreinstalling the game should fix it.", modsDir)
+	}
+	text := "Some of the game's files couldn't be loaded:\n\n" +
+		strings.Join(lines, "\n") +
+		"\n\n" + fix + "\nPress Esc to quit."
+	ebitenutil.DebugPrintAt(screen, text, margin, margin)
+}
+
+// wrapText breaks text into lines no longer than the given number of
+// characters, at spaces where it can
+func wrapText(text string, columns int) []string {
+	var lines []string
+	for len(text) > columns {
+		cut := strings.LastIndex(text[:columns], " ")
+		if cut <= 1 { // No space after the indent
+			cut = columns
+		}
+		lines = append(lines, text[:cut])
+		text = "  " + strings.TrimLeft(text[cut:], " ")
+	}
+	return append(lines, text)
+}
@@ -5,11 +5,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"log"
 	"math"
-	"time"
+	"os"
 
 	"github.com/joelschutz/stagehand"
 	"github.com/sinisterstuf/project-scale/camera"
@@ -20,143 +21,221 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-	input "github.com/quasilyte/ebitengine-input"
+	"github.com/sinisterstuf/project-scale/sim"
 	"github.com/solarlune/ldtkgo"
-	"github.com/solarlune/resolv"
 )
 
+// eventSounds are the sounds the Nanobot makes when things happen to it
+var eventSounds = map[sim.Event]SoundType{
+	sim.EventClimbStep:    sfxClimb,
+	sim.EventJumpEndWall:  sfxGrab,
+	sim.EventJumpEndFloor: sfxGrab,
+	sim.EventFallEnd:      sfxGrab,
+	sim.EventGrapple:      sfxGrab,
+	sim.EventSlipStart:    sfxSlide,
+	sim.EventNoGrip:       sfxSlide,
+	sim.EventSurge:        sfxSplash,
+	sim.EventSubmerge:     sfxDeath,
+	sim.EventSplash:       sfxDeath,
+}
+
 // Length of the fading animation
 const fadeOutTime = 360
-const maxScore = 1000
 const minMinScale = 0.18
 
-const (
-	ActionMoveUp input.Action = iota
-	ActionMoveLeft
-	ActionMoveDown
-	ActionMoveRight
-	ActionPrimary
-	ActionMenu
-)
+// mapFile is the LDtk project with every level of the game
+const mapFile = "assets/maps/Project scale.ldtk"
+
+// hotReload checks for changes to the map and sprites while the game runs so
+// they can be reloaded, it's only set in debug builds
+var hotReload func(g *GameScene)
 
 func NewGameScene(game *Game, loadingState *LoadingState) {
 
 	g := &GameScene{
 		FadeTween: gween.New(0, 255, fadeOutTime, ease.Linear),
-		Debuggers: debuggers,
+		Debuggers: NewDebuggers(game.Debug),
 	}
 
-	loadingState.IncreaseCounter(1)
-	// Pre-render map
-	g.LDTKProject = loadMaps("assets/maps/Project scale.ldtk")
+	// Load maps
+	loadingState.Loading(mapFile)
+	g.LDTKProject = loadMaps(mapFile)
+	loadingState.Done()
 	g.TileRenderer = NewTileRenderer(&EmbedLoader{"assets/maps"})
-	level := g.LDTKProject.Levels[g.Level]
-	fg := ebiten.NewImage(level.Width, level.Height)
-	bg := ebiten.NewImage(level.Width, level.Height)
-	g.TileRenderer.Render(level)
-	for _, layer := range g.TileRenderer.RenderedLayers {
-		log.Println("Pre-rendering layer:", layer.Layer.Identifier)
-		switch layer.Layer.Identifier {
-		case LayerInvisible:
-			continue
-		case LayerWalls:
-			fg.DrawImage(layer.Image, &ebiten.DrawImageOptions{})
-		default:
-			bg.DrawImage(layer.Image, &ebiten.DrawImageOptions{})
+	if g.LDTKProject != nil {
+		for _, level := range g.LDTKProject.Levels {
+			if !sim.IsPlayable(level) {
+				log.Println("Skipping level without start and finish:", level.Identifier)
+				continue
+			}
+			if err := sim.ValidateLevel(level); err != nil {
+				loadReport.Add(mapFile, err)
+				continue
+			}
+			g.Levels = append(g.Levels, level)
+			game.Levels = append(game.Levels, level.Identifier)
+		}
+		if len(g.Levels) == 0 {
+			loadReport.Add(mapFile, errors.New("there are no playable levels"))
 		}
 	}
-	g.Background = bg
-	g.Foreground = fg
-	game.Fog = NewFog(float64(level.Height))
-
-	// Backdrop
-	game.Backdrops = NewBackdrops(float64(level.Height))
-
-	// Create space for collision detection
-	g.Space = resolv.NewSpace(level.Width, level.Height, 16, 16)
-
-	// Obstacles
-	for _, layerName := range []string{
-		LayerFloor,
-		LayerWalls,
-		LayerInvisible,
-	} {
-		tilesToObstacles(level.LayerByIdentifier(layerName), g.Space)
+	game.Stat.Load(game.Levels)
+	if game.LevelName != "" {
+		if index, err := findLevel(game.Levels, game.LevelName); err != nil {
+			loadReport.Add("-level "+game.LevelName, err)
+		} else {
+			game.StartLevel, game.Level = index, index
+		}
+	}
+	if game.Replay != nil {
+		game.Level = -1
+		for i, level := range g.Levels {
+			if level.Identifier == game.Replay.Level {
+				game.Level = i
+				if sim.HashLevel(level) != game.Replay.MapHash {
+					log.Println("Replay was recorded on a different version of", level.Identifier, "so it may not play back the same")
+				}
+			}
+		}
+		if game.Level < 0 {
+			loadReport.Add("replay", fmt.Errorf("there's no level %s to play it back on", game.Replay.Level))
+		}
 	}
 
 	// SoundLoops
-	loadingState.IncreaseCounter(1)
-	g.Sounds = make(Sounds, 5)
-	g.Sounds[backgroundMusic] = &Sound{Volume: 0.5}
+	g.Sounds = make(Sounds, soundTypes)
+	g.Sounds[backgroundMusic] = &Sound{Volume: 0.5, Bus: busMusic}
 	g.Sounds[backgroundMusic].AddSound("assets/music/game-music", sampleRate, context, 7)
 
 	// Sounds
-	loadingState.IncreaseCounter(1)
 	g.Sounds[sfxSubmerge] = &Sound{Volume: 0.7}
 	g.Sounds[sfxSubmerge].AddSound("assets/sfx/submerge", sampleRate, context, 1)
 	g.Sounds[sfxSplash] = &Sound{Volume: 0.7}
 	g.Sounds[sfxSplash].AddSound("assets/sfx/splash", sampleRate, context, 1)
 	g.Sounds[sfxUnderwater] = &Sound{Volume: 1}
 	g.Sounds[sfxUnderwater].AddSound("assets/sfx/underwater", sampleRate, context, 1)
-	g.Sounds[voiceGameWon] = &Sound{Volume: 0.5}
+	g.Sounds[voiceGameWon] = &Sound{Volume: 0.5, Bus: busVoice}
 	g.Sounds[voiceGameWon].AddSound("assets/voices/game-won", sampleRate, context, 1)
+	g.Sounds[sfxClimb] = &Sound{Volume: 0.3}
+	g.Sounds[sfxClimb].AddSound("assets/sfx/nanobot-climb", sampleRate, context)
+	g.Sounds[sfxGrab] = &Sound{Volume: 0.5}
+	g.Sounds[sfxGrab].AddSound("assets/sfx/nanobot-grab", sampleRate, context)
+	g.Sounds[sfxSlide] = &Sound{Volume: 0.5}
+	g.Sounds[sfxSlide].AddSound("assets/sfx/nanobot-slide", sampleRate, context, 2)
+	g.Sounds[sfxDeath] = &Sound{Volume: 0.7}
+	g.Sounds[sfxDeath].AddSound("assets/sfx/nanobot-death", sampleRate, context)
+	g.Sounds[sfxDead] = &Sound{Volume: 0.7}
+	g.Sounds[sfxDead].AddSound("assets/sfx/nanobot-dead", sampleRate, context)
 
 	// Entities
-	loadingState.IncreaseCounter(1)
-
-	// Finish point
-	entities := level.LayerByIdentifier(LayerEntities)
-	finishPos := entities.EntityByIdentifier(EntityFinish)
-	finish := resolv.NewObject(
-		float64(finishPos.Position[0]), float64(finishPos.Position[1]),
-		float64(finishPos.Width), float64(finishPos.Height),
-		TagFinish,
-	)
-	finish.SetShape(resolv.NewRectangle(
-		0, 0, // origin
-		float64(finishPos.Width), float64(finishPos.Height),
-	))
-	g.Space.Add(finish)
-
-	// Player setup
-	startPos := entities.EntityByIdentifier(EntityPlayerStart)
-	startCenter := []int{
-		startPos.Position[0] + (startPos.Width / 2),
-		startPos.Position[1] + (startPos.Height / 2),
-	}
-	game.StartPos = startCenter
-	g.Player = NewPlayer(startCenter, game.Camera)
-	g.Player.Input = game.Input
-	g.Space.Add(g.Player.Object)
-
-	game.Water = NewWater(float64(level.Height) + 4*g.Player.Size.Y)
+	g.Player = NewPlayer(game.Camera)
+	g.Ghost = NewGhost(g.Player.Sprite)
+	g.Platforms = NewPlatforms()
+	if !loadReport.Failed() {
+		loadingState.Loading(g.Levels[game.Level].Identifier)
+		if err := g.LoadLevel(game, game.Level); err != nil {
+			loadReport.Add(mapFile, err)
+		}
+	}
+	loadingState.Done()
 
 	// Done
-	loadingState.IncreaseCounter(1)
 	game.Scenes[gameRunning] = g
 	loadingState.SetLoaded(true)
 }
 
+// LoadLevel pre-renders the level at the given index of the campaign and sets
+// up its simulation, if it can't be played the current level is kept
+func (g *GameScene) LoadLevel(game *Game, index int) error {
+	level := g.Levels[index]
+
+	// Simulation, counting ticks instead of real time when recording so that
+	// round times play back the same
+	var clock sim.Clock = &sim.GameClock{}
+	if game.Record != "" || game.Replay != nil {
+		clock = &sim.TickClock{}
+	}
+	world, err := sim.NewWorld(level, g.Player.Sprite.Meta.FrameTags, clock)
+	if err != nil {
+		return err
+	}
+	world.Player.Input = HandlerInput{game.Input}
+	world.SetDifficulty(game.Difficulty)
+	g.Player.Player = world.Player
+	game.World = world
+	g.Level = index
+	game.Stat.Level = level.Identifier
+
+	// Pre-render map
+	fg := NewChunkedImage(level.Width, level.Height, g.TileRenderer.ChunkHeight)
+	bg := NewChunkedImage(level.Width, level.Height, g.TileRenderer.ChunkHeight)
+	g.TileRenderer.Render(level)
+	for _, layer := range g.TileRenderer.RenderedLayers {
+		log.Println("Pre-rendering layer:", layer.Layer.Identifier)
+		switch layer.Layer.Identifier {
+		case sim.LayerInvisible:
+			continue
+		case sim.LayerWalls:
+			fg.DrawChunked(layer.Image)
+		default:
+			bg.DrawChunked(layer.Image)
+		}
+	}
+	g.TileRenderer.Clear()
+	if g.Background != nil {
+		g.Background.Dispose()
+		g.Foreground.Dispose()
+		g.Minimap.Dispose()
+	}
+	g.Background = bg
+	g.Foreground = fg
+	g.Minimap = renderMinimap(bg, game.Height)
+	game.Fog = NewFog(float64(level.Height))
+
+	// Backdrop
+	game.Backdrops = NewBackdrops(float64(level.Height))
+
+	// The tutorial hints are placed by hand for the first level only
+	g.Player.ControlHints = nil
+	if index == 0 {
+		g.Player.ControlHints = NewControlHints()
+	}
+
+	game.Water = NewWater(world.Water)
+	return nil
+}
+
 // GameScene represents the main game state
 type GameScene struct {
 	BaseScene
 	Player       *Player
-	Space        *resolv.Space
+	Ghost        *Ghost
+	Platforms    *Platforms
 	TileRenderer *TileRenderer
 	LDTKProject  *ldtkgo.Project
-	Background   *ebiten.Image
-	Foreground   *ebiten.Image
+	Levels       []*ldtkgo.Level // levels of the campaign in the order they're played
+	Background   *ChunkedImage
+	Foreground   *ChunkedImage
+	Minimap      *ebiten.Image // Silhouette of the background as tall as the screen
 	Level        int
 	Debuggers    Debuggers
 	Sounds       Sounds
 	Alpha        uint8
 	FadeTween    *gween.Tween
+	Recorder     *sim.Recorder    // Records the round when a record file is given
+	Replayer     *sim.ReplayInput // Plays the round back from the replay
+	SplitShown   int              // Index of the split whose time is on screen
+	SplitTicks   int              // How much longer the split's time stays on screen
 }
 
 // Update calculates game logic
 func (g *GameScene) Update() error {
 	g.State.InputSystem.Update()
 
+	if hotReload != nil {
+		hotReload(g)
+	}
+
 	if g.State.Input.ActionIsJustPressed(ActionMenu) {
 		g.SaveLastRender(true)
 		g.SceneManager.SwitchTo(g.State.Scenes[gamePaused])
@@ -170,31 +249,74 @@ func (g *GameScene) Update() error {
 		g.Player.Position.Y = wy
 	}
 
-	// Movement controls
+	if CheatsAllowed && inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		g.State.Water.Paused = !g.State.Water.Paused
+	}
+
+	// Movement controls, water and everything else that can kill or win
+	world := g.State.World
+	world.Step()
 	g.Player.Update()
 
-	pos := GetScoreFromY(int(g.Player.Position.Y), g.State.StartPos[1])
-	if pos > g.State.Stat.LastHighestPoint {
-		g.State.Stat.LastHighestPoint = pos
+	g.State.Stat.LastHighestPoint = world.HighestPoint
+	if g.State.Timer != nil {
+		g.State.Timer.SetTime(world.Elapsed())
+	}
+	if g.SplitTicks > 0 {
+		g.SplitTicks--
 	}
 
-	if g.Player.State != stateWinning && g.CheckFinish() {
-		g.State.Stat.GameEnd = time.Now()
-		g.State.Stat.LastRound = int(g.State.Stat.GameEnd.Sub(g.State.Stat.GameStart).Seconds())
-		if g.State.Stat.FastestRound <= 0 || g.State.Stat.FastestRound > g.State.Stat.LastRound {
-			g.State.Stat.FastestRound = g.State.Stat.LastRound
-			g.State.Stat.Save()
+	for _, event := range world.Events {
+		if sound, ok := eventSounds[event]; ok {
+			g.Sounds[sound].Play()
 		}
-		g.Player.State = stateWinning
-		g.State.minScale = float64(g.State.Camera.Width) / float64(g.State.Backdrops.Backdrops[0].Image.Bounds().Dx()-int(math.Abs(g.Player.Position.X))*2)
-		if g.State.minScale < minMinScale {
-			g.State.minScale = minMinScale
+
+		switch event {
+		case sim.EventSplit:
+			g.SplitShown, g.SplitTicks = len(world.SplitTimes)-1, splitShowTicks
+			if g.State.Timer != nil {
+				g.State.Timer.Split(world.SplitTimes[g.SplitShown])
+			}
+
+		case sim.EventFinish:
+			g.SplitShown, g.SplitTicks = len(world.SplitTimes)-1, splitShowTicks
+			g.State.Stat.LastRound = int(world.Elapsed().Seconds())
+			g.State.Stat.LastTime = world.Elapsed()
+			g.State.Stat.LastSplits = world.SplitTimes
+			if g.State.Timer != nil {
+				g.State.Timer.Split(world.Elapsed())
+			}
+			// Someone else's round played back isn't the player's record
+			if g.Replayer == nil {
+				g.State.Stat.Current().Beat(world.Splits, world.SplitTimes, world.Trace)
+				g.State.Stat.Unlock(g.Level + 1)
+				g.State.Stat.Save()
+			}
+			g.State.minScale = float64(g.State.Camera.Width) / float64(g.State.Backdrops.Backdrops[0].Image.Bounds().Dx()-int(math.Abs(g.Player.Position.X))*2)
+			if g.State.minScale < minMinScale {
+				g.State.minScale = minMinScale
+			}
+			g.Sounds[backgroundMusic].FadeOut(1)
+			g.Sounds[voiceGameWon].Play()
+
+		case sim.EventSubmerge, sim.EventSplash:
+			g.Sounds[backgroundMusic].LowPass(true)
+			g.Sounds[backgroundMusic].FadeOut(2)
+			if event == sim.EventSubmerge {
+				g.Sounds[sfxSubmerge].Play()
+			} else {
+				g.Sounds[sfxSplash].Play()
+			}
+			g.Sounds[sfxUnderwater].Play()
+			g.State.Stat.LastSplits = world.SplitTimes
+			if record := g.State.Stat.Current(); g.Replayer == nil && g.State.Stat.LastHighestPoint > record.HighestPoint {
+				record.HighestPoint = g.State.Stat.LastHighestPoint
+				g.State.Stat.Save()
+			}
 		}
-		g.Sounds[backgroundMusic].FadeOut(1)
-		g.Sounds[voiceGameWon].Play()
 	}
 
-	if g.Player.State == stateWinning {
+	if g.Player.State == sim.StateWinning {
 		if g.State.Camera.Scale > g.State.minScale {
 			g.State.Camera.Zoom(0.99)
 			g.State.Camera.SetPosition(g.Player.Position.X, float64(g.State.Camera.Height/2)/g.State.Camera.Scale)
@@ -202,7 +324,7 @@ func (g *GameScene) Update() error {
 		}
 	} else {
 		// Position camera and clamp in to the Map dimensions
-		maxHeight := g.LDTKProject.Levels[g.Level].Height
+		maxHeight := g.Levels[g.Level].Height
 		g.State.Camera.SetPosition(g.Player.Position.X, math.Min(
 			math.Max(g.Player.Position.Y, float64(g.State.Camera.Height/2)),
 			float64(maxHeight-g.State.Camera.Height/2),
@@ -210,20 +332,23 @@ func (g *GameScene) Update() error {
 		g.State.Camera.Update()
 	}
 
-	if g.Player.State == stateWinning || g.Player.State == stateDying {
+	if g.Player.State == sim.StateWinning || g.Player.State == sim.StateDying {
 		g.Sounds[backgroundMusic].Update()
 	}
 
-	g.State.Water.Update(g.Player.State != stateWinning)
-
 	g.State.Fog.Update()
 
 	switch g.Player.State {
-	case stateDying:
+	case sim.StateDying:
 		alpha, _ := g.FadeTween.Update(1)
 		g.Alpha = uint8(alpha)
 		if g.Alpha == 128 {
-			g.Player.State = stateDead
+			if g.Replayer != nil && g.Replayer.RespawnDue() {
+				g.Respawn()
+				return nil
+			}
+			g.Player.State = sim.StateDead
+			g.Sounds[sfxDead].Play()
 			g.Sounds[backgroundMusic].Pause()
 			g.Sounds[backgroundMusic].LowPass(false)
 			g.SaveLastRender(true)
@@ -231,16 +356,18 @@ func (g *GameScene) Update() error {
 			return nil
 		}
 
-	case stateWinning:
+	case sim.StateWinning:
 		if g.State.Camera.Scale <= g.State.minScale {
 			alpha, _ := g.FadeTween.Update(1)
 			g.Alpha = uint8(alpha)
 			if g.Alpha == 200 {
 				g.SaveLastRender(false)
-				g.State.Stat.LastHighestPoint = maxScore
-				g.State.Stat.HighestPoint = maxScore
-				g.State.Stat.Save()
-				g.Player.State = gameWon
+				g.State.Stat.LastHighestPoint = sim.MaxScore
+				if g.Replayer == nil {
+					g.State.Stat.Current().HighestPoint = sim.MaxScore
+					g.State.Stat.Save()
+				}
+				g.Player.State = sim.StateWon
 				g.SceneManager.SwitchTo(g.State.Scenes[gameWon])
 				return nil
 			}
@@ -249,23 +376,6 @@ func (g *GameScene) Update() error {
 		if !g.Sounds[backgroundMusic].IsPlaying() {
 			g.Sounds[backgroundMusic].PlayNext()
 		}
-		if g.CheckDeath() {
-			g.Sounds[backgroundMusic].LowPass(true)
-			g.Sounds[backgroundMusic].FadeOut(2)
-			if g.Player.State != stateFalling {
-				g.Sounds[sfxSubmerge].Play()
-			} else {
-				g.Sounds[sfxSplash].Play()
-			}
-			g.Sounds[sfxUnderwater].Play()
-			g.Player.State = stateDying
-			g.Player.AnimState = playerFallloop
-			if g.State.Stat.LastHighestPoint > g.State.Stat.HighestPoint {
-				g.State.Stat.HighestPoint = g.State.Stat.LastHighestPoint
-				g.State.Stat.Save()
-			}
-		}
-
 	}
 
 	return nil
@@ -274,17 +384,19 @@ func (g *GameScene) Update() error {
 // Draw draws the game screen by one frame
 func (g *GameScene) Draw(screen *ebiten.Image) {
 	g.State.Camera.Surface.Clear()
-	cameraOrigin := g.State.Camera.GetTranslation(&ebiten.DrawImageOptions{}, 0, 0)
 
 	g.State.Backdrops.Draw(g.State.Camera, g.State.Water.Level)
-	if g.Player.State == stateDying {
-		g.State.Camera.Surface.DrawImage(g.Background, cameraOrigin)
-		g.State.Camera.Surface.DrawImage(g.Foreground, cameraOrigin)
+	g.Background.Draw(g.State.Camera)
+	g.Platforms.Draw(g.State.Camera, g.State.World, g.Player.Tick)
+	if g.State.GhostEnabled && g.Player.State != sim.StateWinning && g.Player.State != sim.StateWon {
+		g.Ghost.Draw(g.State.Camera, g.State.Stat.Current().Ghost, len(g.State.World.Trace)-1)
+	}
+	if g.Player.State == sim.StateDying {
+		g.Foreground.Draw(g.State.Camera)
 		g.Player.Draw(g.State.Camera)
 	} else {
-		g.State.Camera.Surface.DrawImage(g.Background, cameraOrigin)
 		g.Player.Draw(g.State.Camera)
-		g.State.Camera.Surface.DrawImage(g.Foreground, cameraOrigin)
+		g.Foreground.Draw(g.State.Camera)
 		for _, hint := range g.Player.ControlHints {
 			hint.Draw(g.Player.Position.X, g.Player.Position.Y, g.State.Camera)
 		}
@@ -297,69 +409,122 @@ func (g *GameScene) Draw(screen *ebiten.Image) {
 
 	g.State.Camera.Blit(screen)
 
-	if g.Player.State == stateDying || g.Player.State == stateDead || g.Player.State == stateWinning || g.Player.State == stateWon {
+	if g.Player.State == sim.StateDying || g.Player.State == sim.StateDead || g.Player.State == sim.StateWinning || g.Player.State == sim.StateWon {
 		vector.DrawFilledRect(screen, 0, 0, float32(g.State.Width), float32(g.State.Height), color.RGBA{0, 0, 0, g.Alpha}, false)
 	}
 
-	if g.Player.State != stateWinning && g.Player.State != stateWon {
+	if g.Player.State != sim.StateWinning && g.Player.State != sim.StateWon {
 		g.DrawMinimap(screen)
+		g.DrawGrip(screen)
 	}
+	g.DrawTimer(screen)
 	g.Debuggers.Debug(g, screen)
 }
 
 func (g *GameScene) Load(st State, sm *stagehand.SceneManager[State]) {
 	g.BaseScene.Load(st, sm)
+	if clock, ok := g.State.World.Clock.(sim.Pauser); ok {
+		clock.Resume()
+	}
+	if g.State.Timer != nil {
+		g.State.Timer.Resume()
+	}
 	if g.State.ResetNeeded {
 		g.State.ResetNeeded = false
 		g.Reset()
 		g.Sounds[backgroundMusic].PlayNext()
+	} else if g.State.RespawnNeeded {
+		g.State.RespawnNeeded = false
+		g.Respawn()
+		g.Sounds[backgroundMusic].PlayNext()
 	} else {
 		g.Sounds[backgroundMusic].Resume()
 	}
-
 }
 
+// Unload stops the round's clock until the game is played again, so that the
+// time spent in the menus doesn't count
 func (g *GameScene) Unload() State {
+	if clock, ok := g.State.World.Clock.(sim.Pauser); ok {
+		clock.Pause()
+	}
+	if g.State.Timer != nil {
+		g.State.Timer.Pause(g.State.World.Elapsed())
+	}
 	g.Sounds[backgroundMusic].Pause()
 	g.Sounds[sfxUnderwater].Pause()
+	g.Sounds[sfxSlide].Pause()
+	g.SaveRecording()
 
 	return g.BaseScene.Unload()
 }
 
-func (g *GameScene) CheckFinish() bool {
-	if collision := g.Player.Check(0, 0, TagFinish); collision != nil {
-		for _, o := range collision.Objects {
-			if g.Player.Shape.Intersection(0, 0, o.Shape) != nil {
-				return true
-			}
+func (g *GameScene) Reset() {
+	if g.State.Level != g.Level {
+		if err := g.LoadLevel(g.State, g.State.Level); err != nil {
+			log.Println("Playing", g.Levels[g.Level].Identifier, "again:", err)
+			g.State.Level = g.Level
 		}
 	}
-	return false
-}
-
-func (g *GameScene) CheckDeath() bool {
-	// Death by water (water covers the top of you)
-	if g.State.Water.Level < g.Player.Position.Y-g.Player.Size.Y/4 {
-		return true
+	g.State.World.SetDifficulty(g.State.Difficulty)
+	g.State.World.Reset()
+	if g.State.Timer != nil {
+		g.State.Timer.Start()
 	}
-
-	return false
+	g.StartRecording()
+	g.Alpha = 0
+	g.FadeTween.Reset()
+	g.State.Camera.Zoom(1 / g.State.Camera.Scale)
+	g.State.Stat.LastHighestPoint = 0
+	g.State.Stat.LastSplits = nil
+	record := g.State.Stat.Current()
+	record.CheckSplits(g.State.World.Splits)
+	g.State.Stat.PreviousBest = record.Splits
+	g.SplitTicks = 0
 }
 
-func (g *GameScene) Reset() {
-	level := g.LDTKProject.Levels[g.Level]
-	g.Player.Position.X, g.Player.Position.Y = float64(g.State.StartPos[0]), float64(g.State.StartPos[1])
-	g.Player.Facing = directionUp
-	g.Player.AnimState = playerIdle
-	g.Player.State = stateIdle
-	g.Player.Rotation = 0
-	g.State.Water = NewWater(float64(level.Height) + 4*g.Player.Size.Y)
-	g.Sounds[backgroundMusic].SetVolume(0.5)
+// Respawn puts the player back at the last checkpoint they reached and lowers
+// the water to a safe distance below it, the round's clock keeps running
+func (g *GameScene) Respawn() {
+	g.State.World.Respawn()
+	if g.Recorder != nil {
+		g.Recorder.Respawned()
+	}
 	g.Alpha = 0
 	g.FadeTween.Reset()
 	g.State.Camera.Zoom(1 / g.State.Camera.Scale)
-	g.State.Stat.GameStart = time.Now()
-	g.State.Stat.LastHighestPoint = 0
+}
+
+// StartRecording picks where the player's input comes from for a new round,
+// the replay if one is being played back for this level or the controls,
+// which are recorded if a record file was given
+func (g *GameScene) StartRecording() {
+	world := g.State.World
+	controls := HandlerInput{g.State.Input}
+	g.Recorder, g.Replayer = nil, nil
+	world.Player.Input = controls
+
+	if replay := g.State.Replay; replay != nil && replay.Level == world.Level.Identifier {
+		g.Replayer = sim.NewReplayInput(replay)
+		world.Player.Input = g.Replayer
+	} else if g.State.Record != "" {
+		g.Recorder = &sim.Recorder{Input: controls, Replay: sim.NewReplay(world.Level, world.Difficulty)}
+		world.Player.Input = g.Recorder
+	}
+}
+
+// SaveRecording writes the round recorded so far to the record file
+func (g *GameScene) SaveRecording() {
+	if g.Recorder == nil {
+		return
+	}
+	data, err := g.Recorder.Replay.MarshalBinary()
+	if err == nil {
+		err = os.WriteFile(g.State.Record, data, 0o644)
+	}
+	if err != nil {
+		log.Println("Error saving replay:", err)
+	}
 }
 
 type Entity interface {
@@ -401,38 +566,65 @@ func (g *GameScene) DrawMinimap(screen *ebiten.Image) {
 
 	// v2
 	// Draw building
-	scale := float64(g.State.Height) / float64(g.Background.Bounds().Dy())
-	minimapWidth := float64(g.Background.Bounds().Dx()) * scale
+	scale := float64(g.State.Height) / float64(g.Background.Height)
+	minimapWidth := float64(g.Minimap.Bounds().Dx())
 	vector.DrawFilledRect(screen, 0, 0, float32(minimapWidth), float32(g.State.Height), color.RGBA{40, 40, 40, 128}, false)
-
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Scale(scale, scale)
-	op.ColorScale.Scale(0, 0, 0, 255)
-	screen.DrawImage(g.Background, op)
+	screen.DrawImage(g.Minimap, &ebiten.DrawImageOptions{})
 
 	// Draw high score
 	hsColor := color.RGBA{255, 0, 0, 255}
-	hsYPosition := GetYFromScore(g.State.Stat.HighestPoint, g.State.StartPos[1]) * scale
+	highestPoint := g.State.Stat.Current().HighestPoint
+	hsYPosition := sim.GetYFromScore(highestPoint, g.State.World.StartPos[1]) * scale
 	vector.StrokeLine(screen, 0, float32(hsYPosition), 30, float32(hsYPosition), 1, hsColor, false)
-	g.State.TextRenderer.DrawXY(screen, fmt.Sprintf("%d", g.State.Stat.HighestPoint), hsColor, 8, int(minimapWidth+1), int(hsYPosition-8), etxt.Left)
+	g.State.TextRenderer.DrawXY(screen, fmt.Sprintf("%d", highestPoint), hsColor, 8, int(minimapWidth+1), int(hsYPosition-8), etxt.Left)
 
 	// Draw player
 	playerColor := color.RGBA{255, 255, 0, 255}
 	playerXPosition := g.Player.Position.X * scale
 	playerYPosition := g.Player.Position.Y * scale
-	playerHeightValue := GetScoreFromY(int(g.Player.Position.Y), g.State.StartPos[1])
+	playerHeightValue := sim.GetScoreFromY(int(g.Player.Position.Y), g.State.World.StartPos[1])
 	vector.StrokeLine(screen, float32(playerXPosition+3), float32(playerYPosition), 30, float32(playerYPosition), 1, playerColor, false)
 	vector.StrokeLine(screen, float32(playerXPosition-1), float32(playerYPosition), float32(playerXPosition+1), float32(playerYPosition), 1, playerColor, false)
 	g.State.TextRenderer.DrawXY(screen, fmt.Sprintf("%d", playerHeightValue), playerColor, 8, int(minimapWidth+1), int(playerYPosition-8), etxt.Left)
 
+	// Draw checkpoints, the one you'll respawn at stands out
+	for _, checkpoint := range g.State.World.Checkpoints {
+		cpColor := color.RGBA{254, 174, 52, 128}
+		if checkpoint == g.State.World.Checkpoint {
+			cpColor = color.RGBA{254, 174, 52, 255}
+		}
+		cpX, cpY := checkpoint.Center()
+		cpX, cpY = cpX*scale, cpY*scale
+		vector.DrawFilledRect(screen, float32(cpX-1), float32(cpY-1), 3, 3, cpColor, false)
+	}
+
 	// Draw water
 	vector.DrawFilledRect(screen, 0, float32(g.State.Water.Level*scale), float32(minimapWidth), float32(float64(g.State.Height)-g.State.Water.Level*scale), color.RGBA{58, 79, 118, 204}, false)
 }
 
-func GetScoreFromY(position, levelHeight int) int {
-	return int(float64(levelHeight-position) / float64(levelHeight) * float64(maxScore))
+// DrawGrip draws how much grip the player has left as a bar in the bottom
+// right corner, which changes colour like the light when it runs low
+func (g *GameScene) DrawGrip(screen *ebiten.Image) {
+	const width, height, margin = 40, 3, 4
+	x := float32(g.State.Width - width - margin)
+	y := float32(g.State.Height - height - margin)
+	vector.DrawFilledRect(screen, x-1, y-1, width+2, height+2, color.RGBA{40, 40, 40, 128}, false)
+	barColor := g.Player.Light.Color.(color.NRGBA)
+	barColor.A = 255
+	vector.DrawFilledRect(screen, x, y, float32(width*g.Player.Grip/sim.MaxGrip), height, barColor, false)
 }
 
-func GetYFromScore(score, levelHeight int) float64 {
-	return float64(levelHeight) - float64(score)/float64(maxScore)*float64(levelHeight)
+// renderMinimap shrinks the background down to the given height in black, so
+// that it doesn't need to be scaled every frame
+func renderMinimap(background *ChunkedImage, height int) *ebiten.Image {
+	scale := float64(height) / float64(background.Height)
+	minimap := ebiten.NewImage(int(math.Ceil(float64(background.Width)*scale)), height)
+	for i, chunk := range background.Chunks {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(0, float64(i*background.ChunkHeight))
+		op.GeoM.Scale(scale, scale)
+		op.ColorScale.Scale(0, 0, 0, 255)
+		minimap.DrawImage(chunk, op)
+	}
+	return minimap
 }
@@ -0,0 +1,33 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/project-scale/camera"
+	"github.com/sinisterstuf/project-scale/sim"
+)
+
+// Ghost is a see-through Nanobot that climbs along the path of your fastest
+// round so you can race against it
+type Ghost struct {
+	Sprite *SpriteSheet
+	Alpha  float32
+}
+
+func NewGhost(sprite *SpriteSheet) *Ghost {
+	return &Ghost{Sprite: sprite, Alpha: 0.4}
+}
+
+// Draw draws the ghost where it was at the same tick of its round, it
+// disappears once its round is over
+func (g *Ghost) Draw(camera *camera.Camera, trace sim.Trace, tick int) {
+	point, ok := trace.At(tick)
+	if !ok || int(point.Frame) >= len(g.Sprite.Sprite) {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(g.Alpha)
+	drawNanobot(camera, g.Sprite, int(point.Frame), float64(point.Facing), float64(point.X), float64(point.Y), op)
+}
@@ -0,0 +1,237 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+//go:build !release
+
+package main
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/project-scale/asset"
+	"github.com/sinisterstuf/project-scale/sim"
+	"github.com/solarlune/ldtkgo"
+)
+
+func init() {
+	reloader := &HotReloader{}
+	hotReload = reloader.Update
+	loadPlayerSprite = loadSpriteWithOSOverride
+}
+
+// How many ticks to wait between checking the files for changes
+const watchTicks = 30
+
+// Sprite sheet that can be overridden from the working directory
+const overrideSprite = "Nanobot"
+
+// HotReloader watches the map, its tilesets and the overridden sprite sheet
+// on disk and reloads whatever changed, keeping the player where they are, so
+// levels can be worked on in LDtk without restarting the game
+type HotReloader struct {
+	Map     map[string]time.Time // Modification times of the map and tilesets
+	Sprites map[string]time.Time // Modification times of the sprite files
+	FS      asset.Layered        // The assets on disk with the mods over them
+	tick    int
+}
+
+// Update checks the files every so often and reloads the ones that changed
+func (r *HotReloader) Update(g *GameScene) {
+	if r.Map == nil {
+		r.FS = slices.Clone(assetFS)
+		r.FS[0] = asset.Layer{Name: gameLayer, FS: os.DirFS(".")}
+		r.Map = modTimes(r.FS, r.mapFiles(g.LDTKProject))
+		r.Sprites = modTimes(os.DirFS("."), []string{overrideSprite + ".json", overrideSprite + ".png"})
+	}
+
+	r.tick++
+	if r.tick%watchTicks != 0 {
+		return
+	}
+
+	if changed(os.DirFS("."), r.Sprites) {
+		log.Println("Reloading sprite", overrideSprite)
+		r.reloadSprite(g)
+	}
+	if changed(r.FS, r.Map) {
+		log.Println("Reloading map", mapFile)
+		r.reloadMap(g)
+	}
+}
+
+// mapFiles are the map and the tilesets it uses
+func (r *HotReloader) mapFiles(project *ldtkgo.Project) []string {
+	files := []string{mapFile}
+	for _, tileset := range project.Tilesets {
+		files = append(files, path.Join(path.Dir(mapFile), tileset.Path))
+	}
+	return files
+}
+
+// modTimes looks up when each file was last changed, files that don't exist
+// have the zero time so they count as changed once they're created
+func modTimes(fsys fs.FS, files []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(files))
+	for _, name := range files {
+		if info, err := fs.Stat(fsys, name); err == nil {
+			times[name] = info.ModTime()
+		} else {
+			times[name] = time.Time{}
+		}
+	}
+	return times
+}
+
+// changed updates the modification times and reports whether any file changed
+func changed(fsys fs.FS, times map[string]time.Time) bool {
+	result := false
+	for name, last := range times {
+		info, err := fs.Stat(fsys, name)
+		if err != nil || info.ModTime().Equal(last) {
+			continue
+		}
+		times[name] = info.ModTime()
+		result = true
+	}
+	return result
+}
+
+func (r *HotReloader) reloadSprite(g *GameScene) {
+	sprite := loadSpriteWithOSOverride(overrideSprite)
+	g.Player.Sprite = sprite
+	g.Ghost.Sprite = sprite
+	g.Player.FrameTags = sprite.Meta.FrameTags
+}
+
+// reloadMap reads the map from disk, or from a mod that replaces it, and
+// rebuilds the current level around the player, if the map has mistakes the
+// old one is kept
+func (r *HotReloader) reloadMap(g *GameScene) {
+	project, err := asset.Project(r.FS, mapFile)
+	if err != nil {
+		log.Println("Keeping the old map:", err)
+		return
+	}
+
+	var levels []*ldtkgo.Level
+	var names []string
+	for _, level := range project.Levels {
+		if !sim.IsPlayable(level) {
+			continue
+		}
+		if err := sim.CheckLayers(level); err != nil {
+			log.Println("Keeping the old map:", err)
+			return
+		}
+		if err := sim.ValidateLevel(level); err != nil {
+			log.Println("Keeping the old map:", err)
+			return
+		}
+		levels = append(levels, level)
+		names = append(names, level.Identifier)
+	}
+	if !slices.Equal(names, g.State.Levels) {
+		log.Println("Keeping the old map: levels were added, removed or reordered, restart the game to play them")
+		return
+	}
+
+	// Start watching tilesets the map didn't use before
+	for name, t := range modTimes(r.FS, r.mapFiles(project)) {
+		if _, ok := r.Map[name]; !ok {
+			r.Map[name] = t
+		}
+	}
+
+	g.LDTKProject = project
+	g.Levels = levels
+	g.TileRenderer.Loader = &OSLoader{path.Dir(mapFile), r.FS}
+	g.TileRenderer.Tilesets = map[string]*ebiten.Image{}
+
+	old := g.State.World
+	if err := g.LoadLevel(g.State, g.Level); err != nil {
+		log.Println("Keeping the old level:", err)
+		return
+	}
+	keepProgress(old, g.State.World)
+}
+
+// keepProgress carries over where the player is and what they're doing, the
+// water, the set off triggers, the split times and the reached checkpoints
+// from the world before reloading
+func keepProgress(old, world *sim.World) {
+	p, o := world.Player, old.Player
+	p.Input = o.Input
+	p.Position = o.Position
+	p.State, p.AnimState = o.State, o.AnimState
+	p.Frame, p.Tick = o.Frame, o.Tick
+	p.JumpFrom, p.GrappleTo = o.JumpFrom, o.GrappleTo
+	p.Facing, p.Rotation = o.Facing, o.Rotation
+	p.Grip = o.Grip
+	p.Object.Update()
+
+	water, ow := world.Water, old.Water
+	water.Level, water.Paused, water.Ticks, water.Tide = ow.Level, ow.Paused, ow.Ticks, ow.Tide
+	water.NextPause, water.PauseLeft = ow.NextPause, ow.PauseLeft
+	water.SurgeSpeed, water.SurgeLeft = ow.SurgeSpeed, ow.SurgeLeft
+	world.Clock, world.Start = old.Clock, old.Start
+	world.HighestPoint = old.HighestPoint
+	world.Trace = old.Trace
+
+	if len(world.Triggers) == len(old.Triggers) {
+		for i, trigger := range old.Triggers {
+			world.Triggers[i].Triggered = trigger.Triggered
+		}
+	}
+
+	if len(world.Splits) == len(old.Splits) {
+		world.SplitTimes = old.SplitTimes
+	}
+
+	if len(world.Checkpoints) == len(old.Checkpoints) {
+		for i, checkpoint := range old.Checkpoints {
+			world.Checkpoints[i].Reached = checkpoint.Reached
+			if checkpoint == old.Checkpoint {
+				world.Checkpoint = world.Checkpoints[i]
+			}
+		}
+	}
+}
+
+// loadSpriteWithOSOverride loads a sprite from the working directory, or from
+// the assets if it isn't there
+func loadSpriteWithOSOverride(name string) *SpriteSheet {
+	log.Printf("attempting loading %s\n from OS", name)
+
+	ss, err := asset.Sprite(os.DirFS("."), name)
+	if err != nil {
+		log.Printf("error loading sprite from OS %s: %v\n", name, err)
+		log.Println("delegating opening file to internal assets")
+		return loadSprite(name)
+	}
+	return ss
+}
+
+// OSLoader is a TilesetLoader that reads tilesets from disk, or from a mod that
+// replaces them, so that changes to them show up without rebuilding the game,
+// falling back to the embedded FS
+type OSLoader struct {
+	BasePath string
+	FS       fs.FS // The assets on disk with the mods over them
+}
+
+// LoadTileset loads an LDtk tileset image from disk
+func (l *OSLoader) LoadTileset(tileSetPath string) *ebiten.Image {
+	name := path.Join(l.BasePath, tileSetPath)
+	image, err := asset.Image(l.FS, name)
+	if err != nil {
+		log.Println("Loading tileset from internal assets:", err)
+		return loadImage(name)
+	}
+	return image
+}
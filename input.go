@@ -0,0 +1,30 @@
+package main
+
+import (
+	input "github.com/quasilyte/ebitengine-input"
+	"github.com/sinisterstuf/project-scale/sim"
+)
+
+const (
+	ActionMoveUp    = input.Action(sim.ActionMoveUp)
+	ActionMoveLeft  = input.Action(sim.ActionMoveLeft)
+	ActionMoveDown  = input.Action(sim.ActionMoveDown)
+	ActionMoveRight = input.Action(sim.ActionMoveRight)
+	ActionPrimary   = input.Action(sim.ActionPrimary)
+	ActionMenu      = input.Action(sim.ActionMenu)
+	ActionSecondary = input.Action(sim.ActionSecondary)
+)
+
+// HandlerInput lets the simulation read the player's actions from an input
+// handler
+type HandlerInput struct {
+	*input.Handler
+}
+
+func (h HandlerInput) ActionIsPressed(action sim.Action) bool {
+	return h.Handler.ActionIsPressed(input.Action(action))
+}
+
+func (h HandlerInput) ActionIsJustPressed(action sim.Action) bool {
+	return h.Handler.ActionIsJustPressed(input.Action(action))
+}
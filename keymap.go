@@ -0,0 +1,97 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	input "github.com/quasilyte/ebitengine-input"
+)
+
+// actionNames are what the actions are called on the controls screen and in
+// the saved keymap, in the order they're listed
+var actionNames = []string{
+	ActionMoveUp:    "Climb up",
+	ActionMoveLeft:  "Climb left",
+	ActionMoveDown:  "Climb down",
+	ActionMoveRight: "Climb right",
+	ActionPrimary:   "Jump",
+	ActionMenu:      "Menu",
+	ActionSecondary: "Grapple",
+}
+
+// gamepadKeys are the gamepad buttons and stick directions that can be bound
+// to an action
+var gamepadKeys = []input.Key{
+	input.KeyGamepadA, input.KeyGamepadB, input.KeyGamepadX, input.KeyGamepadY,
+	input.KeyGamepadL1, input.KeyGamepadL2, input.KeyGamepadR1, input.KeyGamepadR2,
+	input.KeyGamepadStart, input.KeyGamepadBack,
+	input.KeyGamepadUp, input.KeyGamepadRight, input.KeyGamepadDown, input.KeyGamepadLeft,
+	input.KeyGamepadLStick, input.KeyGamepadRStick,
+	input.KeyGamepadLStickUp, input.KeyGamepadLStickRight, input.KeyGamepadLStickDown, input.KeyGamepadLStickLeft,
+	input.KeyGamepadRStickUp, input.KeyGamepadRStickRight, input.KeyGamepadRStickDown, input.KeyGamepadRStickLeft,
+}
+
+// DefaultKeymap is how the game is controlled before the player changes
+// anything
+func DefaultKeymap() input.Keymap {
+	return input.Keymap{
+		ActionMoveUp:    {input.KeyUp, input.KeyW, input.KeyGamepadUp, input.KeyGamepadLStickUp},
+		ActionMoveLeft:  {input.KeyLeft, input.KeyA, input.KeyGamepadLeft, input.KeyGamepadLStickLeft},
+		ActionMoveDown:  {input.KeyDown, input.KeyS, input.KeyGamepadDown, input.KeyGamepadLStickDown},
+		ActionMoveRight: {input.KeyRight, input.KeyD, input.KeyGamepadRight, input.KeyGamepadLStickRight},
+		ActionPrimary:   {input.KeySpace, input.KeyGamepadA},
+		ActionMenu:      {input.KeyEscape, input.KeyGamepadStart},
+		ActionSecondary: {input.KeyShift, input.KeyE, input.KeyGamepadX},
+	}
+}
+
+// isGamepadKey tells whether a key is on a gamepad rather than a keyboard
+func isGamepadKey(k input.Key) bool {
+	return strings.HasPrefix(k.String(), "gamepad_")
+}
+
+// LoadKeymap returns the keymap the player saved, actions that weren't saved
+// keep their default keys
+func LoadKeymap() input.Keymap {
+	keymap := DefaultKeymap()
+	m, err := openGameData()
+	if err != nil {
+		return keymap
+	}
+
+	for action, name := range actionNames {
+		result, err := m.LoadItem("Keymap." + name)
+		if err != nil {
+			continue
+		}
+		var keys []input.Key
+		for _, keyName := range strings.Split(string(result), ",") {
+			k, err := input.ParseKey(keyName)
+			if err != nil {
+				log.Println("Ignoring saved key for", name, err)
+				continue
+			}
+			keys = append(keys, k)
+		}
+		if len(keys) > 0 {
+			keymap[input.Action(action)] = keys
+		}
+	}
+	return keymap
+}
+
+// SaveKeymap saves the keys of every action
+func SaveKeymap(keymap input.Keymap) {
+	m, err := openGameData()
+	if err != nil {
+		return
+	}
+
+	for action, name := range actionNames {
+		var keyNames []string
+		for _, k := range keymap[input.Action(action)] {
+			keyNames = append(keyNames, k.String())
+		}
+		m.SaveItem("Keymap."+name, []byte(strings.Join(keyNames, ",")))
+	}
+}
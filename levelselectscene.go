@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joelschutz/stagehand"
+)
+
+// LevelSelectScene lists the levels of the campaign that have been unlocked
+// so you can choose which one to play
+type LevelSelectScene struct {
+	BaseScene
+	Menu *Menu
+}
+
+func (s *LevelSelectScene) Update() error {
+	s.State.InputSystem.Update()
+	s.Menu.Update()
+
+	if s.State.Input.ActionIsJustPressed(ActionMenu) {
+		s.SceneManager.SwitchTo(s.State.Scenes[gameStart])
+		return nil
+	}
+
+	if s.State.Input.ActionIsJustPressed(ActionPrimary) {
+		if s.Menu.Active == len(s.Menu.Items)-1 {
+			s.SceneManager.SwitchTo(s.State.Scenes[gameStart])
+		} else {
+			s.State.Level = s.Menu.Active
+			s.State.ResetNeeded = true
+			s.SceneManager.SwitchTo(s.State.Scenes[gameRunning])
+		}
+	}
+
+	s.State.Fog.Update()
+
+	return nil
+}
+
+func (s *LevelSelectScene) Draw(screen *ebiten.Image) {
+	s.State.TextRenderer.Draw(screen, "Select level", color.White, 8, 50, 10)
+	s.Menu.Draw(screen)
+
+	fogOp := s.State.Fog.GetDrawImageOptions()
+	fogOp.GeoM.Translate(float64(-s.State.Fog.Image.Bounds().Dx()+s.State.World.StartPos[0])/2, -float64(s.State.Fog.Image.Bounds().Dy())+gameHeight)
+	screen.DrawImage(s.State.Fog.Image, fogOp)
+}
+
+func (s *LevelSelectScene) Load(st State, sm *stagehand.SceneManager[State]) {
+	s.BaseScene.Load(st, sm)
+
+	s.Menu.Items = s.Menu.Items[:0]
+	for i, level := range s.State.Levels[:s.State.Stat.Unlocked] {
+		record := s.State.Stat.ForLevel(level)
+		s.Menu.Items = append(s.Menu.Items, fmt.Sprintf("%d. %s - %d m", i+1, level, record.HighestPoint))
+	}
+	s.Menu.Items = append(s.Menu.Items, "Back")
+
+	s.Menu.Active = s.State.Level
+	if s.Menu.Active >= len(s.Menu.Items)-1 {
+		s.Menu.Active = 0
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/aquilax/go-perlin"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/sinisterstuf/project-scale/camera"
+	"github.com/sinisterstuf/project-scale/sim"
 )
 
 var (
@@ -55,24 +56,31 @@ func (l *Light) SetPos(x, y float64) {
 	l.X, l.Y = x, y
 }
 
-func (l *Light) SetColor(state playerAnimationTags) {
+// SetColor changes the colour of the light to warn about falling, slipping or
+// running out of grip
+func (l *Light) SetColor(state sim.PlayerAnimationTags, grip float64) {
 	switch state {
-	case playerFallstart,
-		playerFallloop,
-		playerFallendwall,
-		playerFallendfloor,
-		playerJumpendwall:
+	case sim.PlayerNogrip,
+		sim.PlayerFallstart,
+		sim.PlayerFallloop,
+		sim.PlayerFallendwall,
+		sim.PlayerFallendfloor,
+		sim.PlayerJumpendwall:
 		l.Color = lightBad
-	case playerSlipend,
-		playerSlipstart,
-		playerSliploop:
+	case sim.PlayerSlipend,
+		sim.PlayerSlipstart,
+		sim.PlayerSliploop:
 		l.Color = lightWarn
 	default:
-		l.Color = lightGood
+		if grip < sim.GripLow {
+			l.Color = lightWarn
+		} else {
+			l.Color = lightGood
+		}
 	}
 }
 
-func (l *Light) Draw(cam *camera.Camera, dir Direction, tick int) {
+func (l *Light) Draw(cam *camera.Camera, dir sim.Direction, tick int) {
 	op := &ebiten.DrawImageOptions{}
 	op = cam.GetTranslation(op, l.X, l.Y)
 	op.GeoM.Translate(l.Offset, l.Offset) // centring
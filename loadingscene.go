@@ -5,24 +5,17 @@ package main
 
 import (
 	"image/color"
+	"path"
 	"sync"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 // How long at least to show the loading screen even if everything loads very
 // fast so that it isn't just a black flash
 var loadingSceneMinTime = 3 * 60
 
-var loadingWhat = []string{
-	"",
-	"map",
-	"music",
-	"sounds",
-	"entities",
-	"done",
-}
-
 // LoadingScene is shown while all the assets are loading.
 // When loading is ready it switches to Intro screen
 type LoadingScene struct {
@@ -30,6 +23,7 @@ type LoadingScene struct {
 	Height       int
 	LoadingState LoadingState // what is being loaded
 	Tick         int
+	MinTime      int // Ticks to show it for at least
 	TextRenderer *TextRenderer
 }
 
@@ -37,7 +31,8 @@ func NewLoadingScene() *LoadingScene {
 	return &LoadingScene{
 		Width:        gameWidth,
 		Height:       gameHeight,
-		LoadingState: LoadingState{counter: 0, loaded: false, stateLock: &sync.RWMutex{}},
+		LoadingState: LoadingState{loaded: false, stateLock: &sync.RWMutex{}},
+		MinTime:      loadingSceneMinTime,
 		TextRenderer: NewTextRenderer("assets/fonts/PixelOperator8.ttf"),
 	}
 }
@@ -54,7 +49,7 @@ func (s *LoadingScene) Update() error {
 
 func (s *LoadingScene) IsLoaded() bool {
 	loaded := s.LoadingState.GetLoaded()
-	return loaded && s.Tick > loadingSceneMinTime
+	return loaded && s.Tick > s.MinTime
 }
 
 // Draw renders the start screen to the screen
@@ -65,32 +60,58 @@ func (s *LoadingScene) Draw(screen *ebiten.Image) {
 		color.White, 8, 50, 50,
 	)
 
-	var whatTxt string
-	counter := s.LoadingState.GetCounterValue()
-	if counter < len(loadingWhat) {
-		whatTxt = loadingWhat[counter]
-	}
-	s.TextRenderer.Draw(screen, "Loading..."+whatTxt, color.White, 8, 50, 85)
-
+	current, progress := s.LoadingState.GetProgress()
+	const width, height = 160, 4
+	x := float32(s.Width-width) / 2
+	y := float32(s.Height) * 0.8
+	vector.DrawFilledRect(screen, x-1, y-1, width+2, height+2, color.RGBA{40, 40, 40, 255}, false)
+	vector.DrawFilledRect(screen, x, y, float32(width*progress), height, color.White, false)
+	s.TextRenderer.Draw(screen, "Loading... "+path.Base(current), color.White, 8, 50, 85)
 }
 
+// LoadingState is how far along loading the game is, it's safe to use from
+// several goroutines
 type LoadingState struct {
 	stateLock *sync.RWMutex
-	counter   int
+	steps     int    // How many files and other steps there are to load
+	stepsDone int    // How many of them are done
+	current   string // What's being loaded right now
 	loaded    bool
 }
 
-func (state *LoadingState) IncreaseCounter(x int) {
+// Expect adds steps to load, a file or something like parsing the map
+func (state *LoadingState) Expect(steps int) {
 	state.stateLock.Lock()
-	state.counter += x
+	state.steps += steps
 	state.stateLock.Unlock()
 }
 
-func (state *LoadingState) GetCounterValue() (x int) {
+// Loading shows what's being loaded right now
+func (state *LoadingState) Loading(name string) {
 	state.stateLock.Lock()
-	x = state.counter
+	state.current = name
 	state.stateLock.Unlock()
-	return
+}
+
+// Done counts a step as loaded
+func (state *LoadingState) Done() {
+	state.stateLock.Lock()
+	state.stepsDone++
+	state.stateLock.Unlock()
+}
+
+// GetProgress returns what's being loaded and how many of the steps are done,
+// from 0 to 1
+func (state *LoadingState) GetProgress() (current string, progress float64) {
+	state.stateLock.RLock()
+	defer state.stateLock.RUnlock()
+	switch {
+	case state.loaded:
+		progress = 1
+	case state.steps > 0:
+		progress = float64(state.stepsDone) / float64(state.steps)
+	}
+	return state.current, progress
 }
 
 func (state *LoadingState) SetLoaded(x bool) {
@@ -1,23 +1,68 @@
 package main
 
 import (
+	"flag"
 	"image"
 	"log"
+	"maps"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/project-scale/sim"
 )
 
 const gameWidth, gameHeight = 320, 240
-const screenScaleFactor = 4
-const gridSize = 16
+const gridSize = sim.GridSize
 
 // CheatsAllowed controls that are useful for game testing but would otherwise
 // be considered cheating, like click to reposition or M to stop water
 var CheatsAllowed bool
 
+// Options are the settings given on the command line
+type Options struct {
+	Level      string // Identifier or number of the level to start at
+	Scale      int    // Window scale for this game, the saved setting if 0
+	Fullscreen *bool  // Full-screen for this game, the saved setting if nil
+	Mute       bool
+	Seed       int64  // Seed of the random choice of sounds and music, random if 0
+	Record     string // File to save a replay of each round to
+	Replay     string // Replay file to play back instead of reading the controls
+	Timer      string // Address to serve split timing to speedrun timers on
+	SkipIntro  bool   // Go straight into the level without the loading and start screens
+	Debug      string // Comma-separated names of the debug overlays to show
+	Mods       string // Folder to look for mods in
+}
+
 func main() {
+	var opts Options
+	flag.StringVar(&opts.Level, "level", "", "start at the `level` with this identifier or number")
+	flag.IntVar(&opts.Scale, "scale", 0, "scale the window up by `n` times")
+	flag.BoolFunc("fullscreen", "play full-screen", func(value string) error {
+		fullscreen, err := strconv.ParseBool(value)
+		opts.Fullscreen = &fullscreen
+		return err
+	})
+	flag.BoolVar(&opts.Mute, "mute", false, "turn off all sound")
+	flag.Int64Var(&opts.Seed, "seed", 0, "pick sound effects and shuffle music with the random `seed`, to hear them in the same order every time")
+	flag.StringVar(&opts.Record, "record", "", "save a replay of each round to `file`")
+	flag.StringVar(&opts.Replay, "replay", "", "play back the replay in `file`")
+	flag.StringVar(&opts.Timer, "timer", "", "serve split timing to speedrun timers on `address`, like localhost:16834")
+	flag.BoolVar(&opts.SkipIntro, "skip-intro", false, "go straight into the level")
+	flag.StringVar(&opts.Mods, "mods", defaultModsDir, "look for mods in `folder`")
+	flag.StringVar(&opts.Debug, "debug", defaultDebugOverlays, "show the debug `overlays` with these comma-separated names, of "+strings.Join(slices.Sorted(maps.Keys(debugOverlays)), ", "))
+	flag.Parse()
 
-	ebiten.SetWindowSize(gameWidth*screenScaleFactor, gameHeight*screenScaleFactor)
+	settings.Load()
+	mixer.Muted = opts.Mute
+	if opts.Seed != 0 {
+		random = rand.New(rand.NewSource(opts.Seed))
+	}
+	settings.Apply()
+	opts.applyWindow()
+	loadMods(opts.Mods)
 	ebiten.SetWindowTitle("Project S.C.A.L.E.")
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowIcon([]image.Image{loadImage("assets/icon.png")})
@@ -25,7 +70,7 @@ func main() {
 		ebiten.SetCursorMode(ebiten.CursorModeHidden)
 	}
 
-	stageManager := NewStageManager()
+	stageManager := NewStageManager(opts)
 
 	go loadGame(stageManager)
 
@@ -33,3 +78,15 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// applyWindow sets up the window as given on the command line, without
+// changing the saved settings so that it's only for this game
+func (o *Options) applyWindow() {
+	if o.Scale != 0 {
+		scale := min(max(o.Scale, minWindowScale), maxWindowScale)
+		ebiten.SetWindowSize(gameWidth*scale, gameHeight*scale)
+	}
+	if o.Fullscreen != nil {
+		ebiten.SetFullscreen(*o.Fullscreen)
+	}
+}
@@ -6,12 +6,10 @@ package main
 import (
 	"bytes"
 	"embed"
-	"encoding/json"
-	"image/png"
-	"io/ioutil"
+	"fmt"
+	"image/color"
 	"log"
 	"math/rand"
-	"os"
 	"path"
 	"strconv"
 	"time"
@@ -19,6 +17,8 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/sinisterstuf/project-scale/asset"
+	"github.com/sinisterstuf/project-scale/sim"
 	"github.com/solarlune/ldtkgo"
 	"github.com/solarlune/resound/effects"
 	"github.com/tanema/gween"
@@ -29,56 +29,47 @@ import (
 //go:embed assets/*
 var assets embed.FS
 
+// random picks which variant of a sound to play and shuffles music, the same
+// seed always picks the same ones
+var random = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// assetFS is where every asset is loaded from, the game's own with the enabled
+// mods layered over them
+var assetFS = asset.Layered{{Name: gameLayer, FS: assets}}
+
+// gameLayer is the name of the layer of the game's own assets
+const gameLayer = "game"
+
 const sampleRate int = 44100 // assuming "normal" sample rate
 var context *audio.Context
 
 var imageCache map[string]*ebiten.Image
+var soundCache map[string]SoundData
 
 func init() {
 	context = audio.NewContext(sampleRate)
 	imageCache = make(map[string]*ebiten.Image)
+	soundCache = make(map[string]SoundData)
 }
 
-// Frame is a single frame of an animation, usually a sub-image of a larger
-// image containing several frames
-type Frame struct {
-	Duration int           `json:"duration"`
-	Position FramePosition `json:"frame"`
-}
-
-// FramePosition represents the position of a frame, including the top-left
-// coordinates and its dimensions (width and height)
-type FramePosition struct {
-	X int `json:"x"`
-	Y int `json:"y"`
-	W int `json:"w"`
-	H int `json:"h"`
-}
+// loadReport is how loading the assets went, the game lists what's wrong
+// instead of starting if any of them couldn't be loaded
+var loadReport = &asset.Report{}
 
 // FrameTags contains tag data about frames to identify different parts of an
 // animation, e.g. idle animation, jump animation frames etc.
-type FrameTags struct {
-	Name      string `json:"name"`
-	From      int    `json:"from"`
-	To        int    `json:"to"`
-	Direction string `json:"direction"`
-}
-
-// Frames is a slice of frames used to create sprite animation
-type Frames []Frame
-
-// SpriteMeta contains sprite meta-data, basically everything except frame data
-type SpriteMeta struct {
-	ImageName string      `json:"image"`
-	FrameTags []FrameTags `json:"frameTags"`
-}
+type FrameTags = sim.FrameTag
 
 // SpriteSheet is the root-node of sprite data, it contains frames and meta data
 // about them
-type SpriteSheet struct {
-	Sprite Frames     `json:"frames"`
-	Meta   SpriteMeta `json:"meta"`
-	Image  *ebiten.Image
+type SpriteSheet = asset.SpriteSheet
+
+// missingImage stands in for an image that couldn't be loaded, so that the
+// game can carry on loading and show what went wrong
+func missingImage() *ebiten.Image {
+	image := ebiten.NewImage(gridSize, gridSize)
+	image.Fill(color.RGBA{255, 0, 255, 255})
+	return image
 }
 
 // Load a sprite image and associated meta-data given a file name (without
@@ -87,57 +78,14 @@ func loadSprite(name string) *SpriteSheet {
 	name = path.Join("assets", "sprites", name)
 	log.Printf("loading %s\n", name)
 
-	file, err := assets.Open(name + ".json")
+	ss, err := asset.SpriteFrames(assetFS, name)
+	loadReport.Add(name+".json", err)
 	if err != nil {
-		log.Fatalf("error opening file %s: %v\n", name, err)
+		log.Printf("error loading sprite %s: %v\n", name, err)
+		ss = &SpriteSheet{}
 	}
-	defer file.Close()
-
-	data, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	var ss SpriteSheet
-	json.Unmarshal(data, &ss)
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	ss.Image = loadImage(name + ".png")
-
-	return &ss
-}
-
-func loadSpriteWithOSOverride(name string) *SpriteSheet {
-	log.Printf("attempting loading %s\n from OS", name)
-
-	file, err := os.Open(name + ".json")
-	if err != nil {
-		log.Printf("error opening file from OS %s: %v\n", name, err)
-		log.Println("delegating opening file to internal assets")
-		return loadSprite(name)
-	}
-	defer file.Close()
-
-	data, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Println(err)
-		log.Println("delegating opening file to internal assets")
-		return loadSprite(name)
-	}
-
-	var ss SpriteSheet
-	json.Unmarshal(data, &ss)
-	if err != nil {
-		log.Println(err)
-		log.Println("delegating opening file to internal assets")
-		return loadSprite(name)
-	}
-
-	ss.Image = loadImageWithOSOverride(name)
-
-	return &ss
+	return ss
 }
 
 // Convenience function to load entity/checkpoint PNGs from the maps folder
@@ -155,77 +103,27 @@ func loadImage(name string) *ebiten.Image {
 
 	log.Printf("loading %s\n", name)
 
-	file, err := assets.Open(name)
+	image, err := asset.Image(assetFS, name)
+	loadReport.Add(name, err)
 	if err != nil {
-		log.Fatalf("error opening file %s: %v\n", name, err)
-	}
-	defer file.Close()
-
-	raw, err := png.Decode(file)
-	if err != nil {
-		log.Fatalf("error decoding file %s as PNG: %v\n", name, err)
-	}
-
-	if raw == nil {
-		log.Fatalf("error empty data for sprite file %s\n", name)
+		log.Printf("error loading image %s: %v\n", name, err)
+		image = missingImage()
 	}
 
-	imageCache[name] = ebiten.NewImageFromImage(raw)
+	imageCache[name] = image
 	return imageCache[name]
 }
 
-func loadImageWithOSOverride(name string) *ebiten.Image {
-
-	name = name + ".png"
-	assetName := path.Join("assets", "sprites", name)
-
-	log.Printf("loading %s\n from OS", name)
-
-	file, err := os.Open(name)
-	if err != nil {
-		log.Printf("error opening file from OS %s: %v\n", name, err)
-		log.Println("delegating opening file to internal assets")
-		return loadImage(assetName)
-	}
-	defer file.Close()
-
-	raw, err := png.Decode(file)
-	if err != nil {
-		log.Printf("error decoding file %s as PNG: %v\n", name, err)
-		log.Println("delegating opening file to internal assets")
-		return loadImage(assetName)
-	}
-
-	if raw == nil {
-		log.Printf("error empty data for sprite file %s\n", name)
-		log.Println("delegating opening file to internal assets")
-		return loadImage(assetName)
-	}
-
-	return ebiten.NewImageFromImage(raw)
-}
-
-// Load an project from embedded FS into an LDtk Project object
+// Load an project from embedded FS into an LDtk Project object, nil if it
+// couldn't be loaded
 func loadMaps(name string) *ldtkgo.Project {
 	log.Printf("loading %s\n", name)
 
-	file, err := assets.Open(name)
-	if err != nil {
-		log.Fatalf("error opening file %s: %v\n", name, err)
-	}
-	defer file.Close()
-
-	data, err := ioutil.ReadAll(file)
-	if err != nil {
-		log.Fatalf("error reading from file %s: %v\n", name, err)
-	}
-
-	// Load the LDtk Project
-	maps, err := ldtkgo.Read(data)
+	maps, err := asset.Project(assetFS, name)
+	loadReport.Add(name, err)
 	if err != nil {
-		log.Fatalf("error parsing file %s as LDtk Project: %v\n", name, err)
+		log.Printf("error loading LDtk project %s: %v\n", name, err)
 	}
-
 	return maps
 }
 
@@ -238,6 +136,12 @@ const (
 	sfxSubmerge
 	sfxUnderwater
 	voiceGameWon
+	sfxClimb
+	sfxGrab
+	sfxSlide
+	sfxDeath
+	sfxDead
+	soundTypes // How many sound types there are
 )
 
 // Sound stores and plays all the sound variants for one single soundType
@@ -245,38 +149,53 @@ type Sound struct {
 	Audio      []SoundData
 	LastPlayed *audio.Player
 	LastIndex  int
-	Volume     float64
+	Volume     float64 // Relative to the bus it's played on
+	Bus        BusName
+	fade       float64 // How much it has faded out, from 0 to 1
 	lowpass    *effects.LowpassFilter
 	tween      *gween.Tween
 }
 
 // AddSound adds one new sound to the soundType
 func (s *Sound) AddSound(f string, sampleRate int, context *audio.Context, v ...int) {
-	var filename string
-
 	variants := 1
 	if len(v) > 0 {
 		variants = v[0]
 	}
 
-	for i := 0; i < variants; i++ {
-		if variants == 1 {
-			filename = f + ".ogg"
-		} else {
-			filename = f + "-" + strconv.Itoa(i+1) + ".ogg"
+	for _, filename := range soundFiles(f, variants) {
+		if data := loadSoundFile(filename, sampleRate); data != nil {
+			s.Audio = append(s.Audio, data)
 		}
+	}
+}
 
-		s.Audio = append(s.Audio, loadSoundFile(filename, sampleRate))
+// soundFiles names the files of a sound's variants, numbered from 1 if there
+// are more than one
+func soundFiles(f string, variants int) []string {
+	if variants == 1 {
+		return []string{f + ".ogg"}
 	}
+	var filenames []string
+	for i := 0; i < variants; i++ {
+		filenames = append(filenames, f+"-"+strconv.Itoa(i+1)+".ogg")
+	}
+	return filenames
 }
 
 // SetVolume sets the volume of the audio
 func (s *Sound) SetVolume(v float64) {
 	if v >= 0 && v <= 1 {
 		s.Volume = v
-		if s.LastPlayed != nil {
-			s.LastPlayed.SetVolume(v)
-		}
+		s.apply()
+	}
+}
+
+// apply sets how loud the audio is playing from its volume, how far it has
+// faded out and the volume of its bus
+func (s *Sound) apply() {
+	if s.LastPlayed != nil {
+		s.LastPlayed.SetVolume(s.Volume * (1 - s.fade) * mixer.Output(s.Bus))
 	}
 }
 
@@ -288,7 +207,7 @@ func (s *Sound) Play() {
 	if length == 0 {
 		return
 	} else if length > 1 {
-		index = rand.Intn(length)
+		index = random.Intn(length)
 	}
 
 	s.PlayVariant(index)
@@ -315,7 +234,10 @@ func (s *Sound) PlayVariant(i int) {
 	s.LastIndex = i
 	s.LastPlayed = audioPlayer
 	s.lowpass = lowpass
-	audioPlayer.SetVolume(s.Volume)
+	s.fade = 0
+	s.tween = nil
+	mixer.add(s)
+	s.apply()
 	audioPlayer.Play()
 }
 
@@ -326,9 +248,10 @@ func (s *Sound) Pause() {
 	}
 }
 
-// Resume resumes the last played audio
+// Resume resumes the last played audio, at the volume that's set now
 func (s *Sound) Resume() {
 	if s.LastPlayed != nil {
+		s.apply()
 		s.LastPlayed.Play()
 	}
 }
@@ -349,24 +272,23 @@ func (s *Sound) IsPlaying() bool {
 
 // LowPass toggles the sound's low-pass filter
 func (s *Sound) LowPass(on bool) {
-	s.lowpass.SetActive(on)
-	if on {
-		s.SetVolume(0.7)
-	} else {
-		s.SetVolume(0.5)
+	if s.lowpass != nil {
+		s.lowpass.SetActive(on)
 	}
 }
 
-// FadeOut fades out the sound smoothly to 0% volume
+// FadeOut fades out the sound smoothly to silence, the volume it's set to
+// stays the same for the next time it's played
 func (s *Sound) FadeOut(duration float32) {
-	s.tween = gween.New(float32(s.Volume), 0, duration*60, ease.InExpo)
+	s.tween = gween.New(float32(1-s.fade), 0, duration*60, ease.InExpo)
 }
 
 // Update the music volume for fade effects
 func (s *Sound) Update() {
 	if s.tween != nil {
-		volume, done := s.tween.Update(1)
-		s.SetVolume(float64(volume))
+		gain, done := s.tween.Update(1)
+		s.fade = 1 - float64(gain)
+		s.apply()
 		if done {
 			s.tween = nil
 			if s.IsPlaying() {
@@ -380,8 +302,7 @@ func (s *Sound) Update() {
 type Sounds []*Sound
 
 func (s *Sound) Shuffle() {
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(s.Audio), func(i, j int) { s.Audio[i], s.Audio[j] = s.Audio[j], s.Audio[i] })
+	random.Shuffle(len(s.Audio), func(i, j int) { s.Audio[i], s.Audio[j] = s.Audio[j], s.Audio[i] })
 }
 
 // MusicLoop is an audio player that infinitely loops back to its start
@@ -417,45 +338,50 @@ func (m *MusicLoop) Update() {
 
 // NewMusicPlayer loads a sound into an audio player that can be used to play it
 // as an infinite loop of music without any additional setup required
-func NewMusicPlayer(data SoundData) *MusicLoop {
+func NewMusicPlayer(data SoundData) (*MusicLoop, error) {
 	music, err := vorbis.DecodeWithoutResampling(bytes.NewReader(data))
 	if err != nil {
-		log.Printf("error decoding sound as Vorbis: %v\n", err)
+		return nil, fmt.Errorf("not an Ogg Vorbis sound: %w", err)
 	}
 
 	musicLoop := audio.NewInfiniteLoop(music, music.Length())
 	musicPlayer, err := audio.NewPlayer(context, musicLoop)
 	if err != nil {
-		log.Fatalf("error making music player: %v\n", err)
+		return nil, fmt.Errorf("error making music player: %w", err)
 	}
-	return &MusicLoop{musicPlayer, nil}
+	return &MusicLoop{musicPlayer, nil}, nil
 }
 
 // SoundData is bytes returned from a sound file
 type SoundData []byte
 
-// Load an OGG Vorbis sound file with 44100 sample rate and return its stream
+// Load an OGG Vorbis sound file with 44100 sample rate and return its stream,
+// nil if it couldn't be loaded
 func loadSoundFile(name string, sampleRate int) SoundData {
-	log.Printf("loading %s\n", name)
-
-	file, err := assets.Open(name)
-	if err != nil {
-		log.Fatalf("error opening file %s: %v\n", name, err)
+	if soundCache[name] != nil {
+		return soundCache[name]
 	}
-	defer file.Close()
 
-	data, err := ioutil.ReadAll(file)
+	log.Printf("loading %s\n", name)
+
+	data, err := asset.Sound(assetFS, name)
+	loadReport.Add(name, err)
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("error loading sound %s: %v\n", name, err)
+		return nil
 	}
 
-	return data
+	soundCache[name] = data
+	return soundCache[name]
 }
 
+// loadFont loads a font, nil if it couldn't be loaded
 func loadFont(name string) *etxt.Font {
-	font, fname, err := etxt.ParseEmbedFontFrom(name, assets)
+	font, fname, err := asset.Font(assetFS, name)
+	loadReport.Add(name, err)
 	if err != nil {
-		log.Fatalf("error parsing font %s: %v", name, err)
+		log.Printf("error loading font %s: %v\n", name, err)
+		return nil
 	}
 
 	log.Println("loaded font:", fname)
@@ -17,10 +17,16 @@ type Menu struct {
 	color         color.Color
 	selectedColor color.Color
 	textRenderer  *TextRenderer
+	sound         *Sound // Played when moving through or choosing an item
 	Input         *input.Handler
 }
 
 func (m *Menu) Update() {
+	if m.sound != nil && (m.Input.ActionIsJustPressed(ActionMoveUp) ||
+		m.Input.ActionIsJustPressed(ActionMoveDown) ||
+		m.Input.ActionIsJustPressed(ActionPrimary)) {
+		m.sound.Play()
+	}
 	if m.Input.ActionIsJustPressed(ActionMoveUp) {
 		m.Active -= 1
 		if m.Active == -1 {
@@ -0,0 +1,90 @@
+package main
+
+// BusName identifies one of the mixer's buses
+type BusName uint8
+
+const (
+	busSFX BusName = iota
+	busMusic
+	busVoice
+)
+
+// How quiet music gets while a voice line is playing, and how fast it gets
+// there and back each tick
+const duckGain, duckSpeed = 0.35, 0.03
+
+// Bus is a group of sounds that share a volume
+type Bus struct {
+	Gain   float64
+	Muted  bool
+	duck   float64 // Extra gain while ducked under another bus
+	sounds []*Sound
+}
+
+// Mixer sets how loud each sound plays from the master volume, the volume of
+// the bus it's on and its own volume
+type Mixer struct {
+	Master float64
+	Muted  bool // Silences everything, whatever the volumes are
+	Buses  []*Bus
+}
+
+// mixer is where every sound in the game is played through
+var mixer = NewMixer()
+
+func NewMixer() *Mixer {
+	return &Mixer{
+		Master: 1,
+		Buses: []*Bus{
+			busSFX:   {Gain: 1, duck: 1},
+			busMusic: {Gain: 1, duck: 1},
+			busVoice: {Gain: 1, duck: 1},
+		},
+	}
+}
+
+// Output is how loud a bus is right now relative to the sounds on it
+func (m *Mixer) Output(name BusName) float64 {
+	bus := m.Buses[name]
+	if m.Muted || bus.Muted {
+		return 0
+	}
+	return m.Master * bus.Gain * bus.duck
+}
+
+// add puts a sound on its bus so it follows changes to the bus's volume
+func (m *Mixer) add(s *Sound) {
+	bus := m.Buses[s.Bus]
+	for _, sound := range bus.sounds {
+		if sound == s {
+			return
+		}
+	}
+	bus.sounds = append(bus.sounds, s)
+}
+
+// Update ducks the music while a voice line plays and updates the volume of
+// every sound that's playing
+func (m *Mixer) Update() {
+	voice := false
+	for _, s := range m.Buses[busVoice].sounds {
+		if s.IsPlaying() {
+			voice = true
+		}
+	}
+
+	music := m.Buses[busMusic]
+	if voice {
+		music.duck = max(duckGain, music.duck-duckSpeed)
+	} else {
+		music.duck = min(1, music.duck+duckSpeed)
+	}
+
+	for _, bus := range m.Buses {
+		for _, s := range bus.sounds {
+			if s.IsPlaying() {
+				s.apply()
+			}
+		}
+	}
+}
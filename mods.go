@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/sinisterstuf/project-scale/asset"
+)
+
+// defaultModsDir is where mods are looked for unless the -mods flag says
+// otherwise
+const defaultModsDir = "mods"
+
+// mods are the installed mods in load order, whether they're turned on or not
+var mods []*asset.Mod
+
+// modsDir is the folder the mods were found in
+var modsDir string
+
+// loadedMods are the folders of the mods whose assets are being used
+var loadedMods []string
+
+// loadMods finds the mods in a folder and layers the ones that are turned on
+// over the game's own assets
+func loadMods(dir string) {
+	modsDir = dir
+	found, err := asset.FindMods(dir)
+	if err != nil {
+		log.Println("Not loading mods:", err)
+	}
+	mods = found
+
+	for _, mod := range mods {
+		if modEnabled(mod) {
+			log.Println("Loading mod", mod.Name, "from", mod.Dir)
+			assetFS = append(assetFS, asset.Layer{Name: mod.Name, FS: os.DirFS(mod.Dir)})
+			loadedMods = append(loadedMods, mod.Dir)
+		}
+	}
+}
+
+// modEnabled tells whether a mod is turned on, mods are on until they're
+// turned off in the Mods list
+func modEnabled(mod *asset.Mod) bool {
+	return !slices.Contains(settings.DisabledMods, filepath.Base(mod.Dir))
+}
+
+// toggleMod turns a mod on or off the next time the game starts
+func toggleMod(mod *asset.Mod) {
+	id := filepath.Base(mod.Dir)
+	if i := slices.Index(settings.DisabledMods, id); i >= 0 {
+		settings.DisabledMods = slices.Delete(settings.DisabledMods, i, i+1)
+	} else {
+		settings.DisabledMods = append(settings.DisabledMods, id)
+	}
+	settings.Save()
+}
+
+// modsChanged tells whether mods were turned on or off since the game started
+func modsChanged() bool {
+	var enabled []string
+	for _, mod := range mods {
+		if modEnabled(mod) {
+			enabled = append(enabled, mod.Dir)
+		}
+	}
+	return !slices.Equal(enabled, loadedMods)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joelschutz/stagehand"
+	"github.com/sinisterstuf/project-scale/asset"
+)
+
+// ModsScene lists the installed mods in load order and lets the player turn
+// them on and off, which takes effect the next time the game starts
+type ModsScene struct {
+	BaseScene
+	Menu *Menu
+}
+
+func NewModsScene(game *Game) *ModsScene {
+	return &ModsScene{
+		Menu: &Menu{
+			X:             gameWidth / 2,
+			Y:             50,
+			color:         color.RGBA{255, 255, 255, 255},
+			selectedColor: color.RGBA{255, 255, 0, 255},
+			textRenderer:  game.TextRenderer,
+			sound:         game.MenuSound,
+			Input:         game.Input,
+		},
+	}
+}
+
+func (s *ModsScene) Update() error {
+	s.State.InputSystem.Update()
+	s.Menu.Update()
+
+	if s.State.Input.ActionIsJustPressed(ActionMenu) {
+		s.SceneManager.SwitchTo(s.State.Scenes[gameStart])
+		return nil
+	}
+
+	if s.State.Input.ActionIsJustPressed(ActionPrimary) {
+		if s.Menu.Active == len(s.Menu.Items)-1 {
+			s.SceneManager.SwitchTo(s.State.Scenes[gameStart])
+			return nil
+		}
+		mod := mods[s.Menu.Active]
+		toggleMod(mod)
+		s.Menu.Items[s.Menu.Active] = modMenuItem(mod)
+	}
+
+	s.State.Fog.Update()
+
+	return nil
+}
+
+func (s *ModsScene) Draw(screen *ebiten.Image) {
+	s.State.TextRenderer.Draw(screen, "Mods", color.White, 8, 50, 10)
+	s.Menu.Draw(screen)
+
+	var info string
+	switch {
+	case len(mods) == 0:
+		info = fmt.Sprintf("No mods installed,\nput them in the %s folder", modsDir)
+	case s.Menu.Active < len(mods):
+		mod := mods[s.Menu.Active]
+		info = mod.Description
+		if mod.Author != "" {
+			info = "By " + mod.Author + "\n" + info
+		}
+	}
+	if modsChanged() {
+		info += "\n\nRestart the game to apply the changes"
+	}
+	s.State.TextRenderer.Draw(screen, info, color.White, 8, 50, 80)
+
+	fogOp := s.State.Fog.GetDrawImageOptions()
+	fogOp.GeoM.Translate(float64(-s.State.Fog.Image.Bounds().Dx()+s.State.World.StartPos[0])/2, -float64(s.State.Fog.Image.Bounds().Dy())+gameHeight)
+	screen.DrawImage(s.State.Fog.Image, fogOp)
+}
+
+func (s *ModsScene) Load(st State, sm *stagehand.SceneManager[State]) {
+	s.BaseScene.Load(st, sm)
+
+	s.Menu.Items = s.Menu.Items[:0]
+	for _, mod := range mods {
+		s.Menu.Items = append(s.Menu.Items, modMenuItem(mod))
+	}
+	s.Menu.Items = append(s.Menu.Items, "Back")
+	s.Menu.Active = 0
+}
+
+// modMenuItem is the label of the menu item that turns a mod on or off
+func modMenuItem(mod *asset.Mod) string {
+	name := mod.Name
+	if mod.Version != "" {
+		name += " " + mod.Version
+	}
+	if modEnabled(mod) {
+		return name + ": ON"
+	}
+	return name + ": OFF"
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/joelschutz/stagehand"
+)
+
+// Menu items of the options scene in the order they're listed
+const (
+	optionMasterVolume = iota
+	optionMusicVolume
+	optionSFXVolume
+	optionVoiceVolume
+	optionFullscreen
+	optionWindowScale
+	optionScreenShake
+	optionVSync
+	optionControls
+	optionCustomDifficulty
+	optionBack
+)
+
+// How much a volume changes with each press
+const volumeStep = 10
+
+// OptionsScene is where the player changes the settings, left and right
+// change the selected setting, the action button mutes volumes
+type OptionsScene struct {
+	BaseScene
+	Menu *Menu
+}
+
+func (s *OptionsScene) Update() error {
+	s.State.InputSystem.Update()
+	s.Menu.Update()
+
+	if s.State.Input.ActionIsJustPressed(ActionMenu) ||
+		s.State.Input.ActionIsJustPressed(ActionPrimary) && s.Menu.Active == optionBack {
+		s.SceneManager.SwitchTo(s.State.Scenes[s.State.OptionsFrom])
+		return nil
+	}
+	if s.State.Input.ActionIsJustPressed(ActionPrimary) && s.Menu.Active == optionControls {
+		s.SceneManager.SwitchTo(s.State.Scenes[gameControls])
+		return nil
+	}
+	if s.State.Input.ActionIsJustPressed(ActionPrimary) && s.Menu.Active == optionCustomDifficulty {
+		s.SceneManager.SwitchTo(s.State.Scenes[gameCustomDifficulty])
+		return nil
+	}
+
+	change := 0
+	if s.State.Input.ActionIsJustPressed(ActionMoveLeft) {
+		change = -1
+	}
+	if s.State.Input.ActionIsJustPressed(ActionMoveRight) {
+		change = 1
+	}
+	if change != 0 {
+		s.change(change)
+	} else if s.State.Input.ActionIsJustPressed(ActionPrimary) {
+		s.toggle()
+	}
+
+	s.State.Fog.Update()
+
+	return nil
+}
+
+// change steps the selected setting up or down, or toggles it
+func (s *OptionsScene) change(step int) {
+	switch s.Menu.Active {
+	case optionMasterVolume:
+		settings.MasterVolume = clamp(settings.MasterVolume+step*volumeStep, 0, 100)
+	case optionMusicVolume:
+		settings.MusicVolume = clamp(settings.MusicVolume+step*volumeStep, 0, 100)
+	case optionSFXVolume:
+		settings.SFXVolume = clamp(settings.SFXVolume+step*volumeStep, 0, 100)
+	case optionVoiceVolume:
+		settings.VoiceVolume = clamp(settings.VoiceVolume+step*volumeStep, 0, 100)
+	case optionFullscreen:
+		settings.Fullscreen = !settings.Fullscreen
+	case optionWindowScale:
+		settings.WindowScale = clamp(settings.WindowScale+step, minWindowScale, maxWindowScale)
+	case optionScreenShake:
+		settings.ScreenShake = !settings.ScreenShake
+	case optionVSync:
+		settings.VSync = !settings.VSync
+	}
+	settings.Apply()
+	s.updateItems()
+}
+
+// toggle mutes or unmutes the selected volume, or changes any other setting
+// as if right was pressed
+func (s *OptionsScene) toggle() {
+	switch s.Menu.Active {
+	case optionMusicVolume:
+		settings.MusicMuted = !settings.MusicMuted
+	case optionSFXVolume:
+		settings.SFXMuted = !settings.SFXMuted
+	case optionVoiceVolume:
+		settings.VoiceMuted = !settings.VoiceMuted
+	default:
+		s.change(1)
+		return
+	}
+	settings.Apply()
+	s.updateItems()
+}
+
+// updateItems shows the current value of each setting in the menu
+func (s *OptionsScene) updateItems() {
+	onOff := func(on bool) string {
+		if on {
+			return "ON"
+		}
+		return "OFF"
+	}
+	volume := func(name string, percent int, muted bool) string {
+		if muted {
+			return name + " volume: MUTED"
+		}
+		return fmt.Sprintf("%s volume: %d%%", name, percent)
+	}
+	s.Menu.Items = []string{
+		volume("Master", settings.MasterVolume, false),
+		volume("Music", settings.MusicVolume, settings.MusicMuted),
+		volume("Sound effects", settings.SFXVolume, settings.SFXMuted),
+		volume("Voice", settings.VoiceVolume, settings.VoiceMuted),
+		"Fullscreen: " + onOff(settings.Fullscreen),
+		fmt.Sprintf("Window size: %dx", settings.WindowScale),
+		"Screen shake: " + onOff(settings.ScreenShake),
+		"VSync: " + onOff(settings.VSync),
+		"Controls",
+		"Custom difficulty",
+		"Back",
+	}
+}
+
+func (s *OptionsScene) Draw(screen *ebiten.Image) {
+	drawOptionsBackground(s.State, screen)
+
+	s.State.TextRenderer.Draw(screen, "Options", color.White, 8, 50, 10)
+	s.Menu.Draw(screen)
+}
+
+// drawOptionsBackground draws the paused game behind the options if they were
+// opened from the pause menu, or the fog of the main menu
+func drawOptionsBackground(game *Game, screen *ebiten.Image) {
+	if game.OptionsFrom == gamePaused {
+		screen.DrawImage(game.lastRender, &ebiten.DrawImageOptions{})
+		vector.DrawFilledRect(screen, 0, 0, float32(game.Width), float32(game.Height), color.RGBA{0, 0, 0, 192}, false)
+	} else {
+		fogOp := game.Fog.GetDrawImageOptions()
+		fogOp.GeoM.Translate(float64(-game.Fog.Image.Bounds().Dx()+game.World.StartPos[0])/2, -float64(game.Fog.Image.Bounds().Dy())+gameHeight)
+		screen.DrawImage(game.Fog.Image, fogOp)
+	}
+}
+
+func (s *OptionsScene) Load(st State, sm *stagehand.SceneManager[State]) {
+	s.BaseScene.Load(st, sm)
+	s.Menu.Active = 0
+	s.updateItems()
+}
+
+func (s *OptionsScene) Unload() State {
+	settings.Save()
+	return s.BaseScene.Unload()
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -5,6 +5,8 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joelschutz/stagehand"
+	"github.com/tinne26/etxt"
 )
 
 // OverScene is shown when the player dies and the game is over
@@ -16,16 +18,33 @@ type OverScene struct {
 func (s *OverScene) Update() error {
 	s.Menu.Update()
 	if s.State.Input.ActionIsJustPressed(ActionPrimary) {
-		if s.Menu.Active == 0 {
+		active := s.Menu.Active
+		if s.State.World.Checkpoint == nil {
+			active++ // there's no "Continue from checkpoint" item to skip over
+		}
+		if active == 0 {
+			s.State.RespawnNeeded = true
+			s.SceneManager.SwitchTo(s.State.Scenes[gameRunning])
+		} else if active == 1 {
 			s.State.ResetNeeded = true
 			s.SceneManager.SwitchTo(s.State.Scenes[gameRunning])
-		} else if s.Menu.Active == 1 {
+		} else if active == 2 {
 			s.SceneManager.SwitchTo(s.State.Scenes[gameStart])
 		}
 	}
 	return nil
 }
 
+func (s *OverScene) Load(st State, sm *stagehand.SceneManager[State]) {
+	s.BaseScene.Load(st, sm)
+	s.Menu.Active = 0
+	if s.State.World.Checkpoint != nil {
+		s.Menu.Items = []string{"Continue from checkpoint", "Restart", "Back to main menu"}
+	} else {
+		s.Menu.Items = []string{"Restart", "Back to main menu"}
+	}
+}
+
 func (s *OverScene) Draw(screen *ebiten.Image) {
 	screen.DrawImage(s.State.lastRender, &ebiten.DrawImageOptions{})
 
@@ -33,22 +52,27 @@ func (s *OverScene) Draw(screen *ebiten.Image) {
 
 	s.Menu.Draw(screen)
 
-	if s.State.Stat.HighestPoint == s.State.Stat.LastHighestPoint {
+	s.State.TextRenderer.DrawXY(screen, splitsSummary(
+		s.State.World.Splits, s.State.Stat.LastSplits, s.State.Stat.PreviousBest,
+	), color.White, 8, gameWidth/2, 120, etxt.XCenter)
+
+	record := s.State.Stat.Current()
+	if record.HighestPoint == s.State.Stat.LastHighestPoint {
 		s.State.BoldTextRenderer.Draw(screen, fmt.Sprintf(
 			"NEW HIGH SCORE!\n\nYou reached %d m",
-			s.State.Stat.HighestPoint,
+			record.HighestPoint,
 		), color.RGBA{255, 255, 0, 255}, 8, 50, 40)
 	} else {
-		if s.State.Stat.FastestRound > 0 {
+		if record.FastestTime > 0 {
 			s.State.TextRenderer.Draw(screen, fmt.Sprintf(
-				"Your last climb: %d m\nYour best climb so far: %d m\nYour fastest victory: %d min %d sec",
-				s.State.Stat.LastHighestPoint, s.State.Stat.HighestPoint, int(s.State.Stat.FastestRound/60), int(s.State.Stat.FastestRound)%60,
+				"Your last climb: %d m\nYour best climb so far: %d m\nYour fastest victory: %s",
+				s.State.Stat.LastHighestPoint, record.HighestPoint, formatTime(record.FastestTime),
 			), color.White, 8, 50, 40)
 
 		} else {
 			s.State.TextRenderer.Draw(screen, fmt.Sprintf(
 				"Your last climb: %d m\nYour best climb so far: %d m",
-				s.State.Stat.LastHighestPoint, s.State.Stat.HighestPoint,
+				s.State.Stat.LastHighestPoint, record.HighestPoint,
 			), color.White, 8, 50, 40)
 		}
 	}
@@ -19,6 +19,9 @@ func (p *PauseScreen) Update() error {
 		if p.Menu.Active == 0 {
 			p.SceneManager.SwitchTo(p.State.Scenes[gameRunning])
 		} else if p.Menu.Active == 1 {
+			p.State.OptionsFrom = gamePaused
+			p.SceneManager.SwitchTo(p.State.Scenes[gameOptions])
+		} else if p.Menu.Active == 2 {
 			p.SceneManager.SwitchTo(p.State.Scenes[gameStart])
 		}
 	}
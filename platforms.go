@@ -0,0 +1,73 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package main
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/project-scale/camera"
+	"github.com/sinisterstuf/project-scale/sim"
+)
+
+//go:generate tools/gen_sprite_tags.sh assets/sprites/Platforms.json platforms_anim.go Platforms
+
+// Platforms is how the moving platforms and crumbling tiles of a level look,
+// each is drawn as a row of tiles as big as its entity in LDtk
+type Platforms struct {
+	Sprite *SpriteSheet
+}
+
+func NewPlatforms() *Platforms {
+	return &Platforms{Sprite: loadSprite("Platforms")}
+}
+
+// Draw draws every platform and crumbling tile of the world, tick keeps the
+// moving platforms' lights going
+func (p *Platforms) Draw(camera *camera.Camera, world *sim.World, tick int) {
+	for _, platform := range world.Platforms {
+		p.drawTiles(camera, platform.Object.Position.X, platform.Object.Position.Y, platform.Size.X, platform.Size.Y,
+			p.loop(PlatformsMoving, tick), 0)
+	}
+
+	for _, tile := range world.Crumbling {
+		frame, dy := p.Sprite.Meta.FrameTags[PlatformsIntact].From, 0.0
+		switch tile.State {
+		case sim.CrumbleShaking:
+			frame = p.loop(PlatformsShaking, tile.Age)
+		case sim.CrumbleCrumbled:
+			tag := p.Sprite.Meta.FrameTags[PlatformsCrumbled]
+			frame = tag.From + tile.Age/sim.AnimationSkipTicks
+			if frame > tag.To {
+				continue
+			}
+			dy = float64(tile.Age) / 4 // Pieces fall further than the frame is tall
+		}
+		p.drawTiles(camera, tile.Position.X, tile.Position.Y, tile.Size.X, tile.Size.Y, frame, dy)
+	}
+}
+
+// loop is the frame of an animation that repeats as the ticks go by
+func (p *Platforms) loop(anim PlatformsAnimationTags, tick int) int {
+	tag := p.Sprite.Meta.FrameTags[anim]
+	return tag.From + tick/sim.AnimationSkipTicks%(tag.To-tag.From+1)
+}
+
+// drawTiles fills the area with a frame of the sprite, moved down by dy
+func (p *Platforms) drawTiles(camera *camera.Camera, x, y, w, h float64, index int, dy float64) {
+	frame := p.Sprite.Sprite[index].Position
+	img := p.Sprite.Image.SubImage(image.Rect(
+		frame.X,
+		frame.Y,
+		frame.X+frame.W,
+		frame.Y+frame.H,
+	)).(*ebiten.Image)
+
+	for ty := 0.0; ty < h; ty += float64(frame.H) {
+		for tx := 0.0; tx < w; tx += float64(frame.W) {
+			op := camera.GetTranslation(&ebiten.DrawImageOptions{}, x+tx, y+ty+dy)
+			camera.Surface.DrawImage(img, op)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+// DO NOT EDIT
+// Generated by: tools/gen_sprite_tags.sh
+
+type PlatformsAnimationTags uint8
+
+const (
+	PlatformsMoving PlatformsAnimationTags = iota
+	PlatformsIntact
+	PlatformsShaking
+	PlatformsCrumbled
+)
+
+var PlatformsAnimationNames = []string{
+	"Moving",
+	"Intact",
+	"Shaking",
+	"Crumbled",
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log"
+	"slices"
+
+	"github.com/sinisterstuf/project-scale/asset"
+)
+
+// preloadImages are the images the scenes load, the tilesets are loaded along
+// with the level they're used in
+var preloadImages = []string{
+	"assets/icon.png",
+	"assets/light.png",
+	"assets/sprites/Controls.png",
+	"assets/sprites/Menu.png",
+	"assets/sprites/Nanobot.png",
+	"assets/sprites/Platforms.png",
+	"assets/sprites/Start button.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0000_Water-1.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0001_Smog-1-cloud.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0002_City-1.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0003_Water-2.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0004_Smog-2.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0005_City-2.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0006_Water-3.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0007_Smog-3.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0008_City-3.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0009_Water-4.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0010_Smog-4.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0011_City-4.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0012_Water-5.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0013_Smog-5.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0014_Sky.png",
+	"assets/backdrop/Project-scale-parallax-backdrop_0015_Background.png",
+}
+
+// preloadSounds are the sounds the scenes load, with all their variants
+var preloadSounds = slices.Concat(
+	soundFiles("assets/music/game-music", 7),
+	soundFiles("assets/sfx/heartbeat", 1),
+	soundFiles("assets/sfx/menu-button", 1),
+	soundFiles("assets/sfx/nanobot-climb", 1),
+	soundFiles("assets/sfx/nanobot-dead", 1),
+	soundFiles("assets/sfx/nanobot-death", 1),
+	soundFiles("assets/sfx/nanobot-grab", 1),
+	soundFiles("assets/sfx/nanobot-slide", 2),
+	soundFiles("assets/sfx/splash", 1),
+	soundFiles("assets/sfx/submerge", 1),
+	soundFiles("assets/sfx/underwater", 1),
+	soundFiles("assets/voices/game-start", 1),
+	soundFiles("assets/voices/game-won", 1),
+)
+
+// gameSceneSteps is how many steps of loading NewGameScene counts, parsing the
+// map and pre-rendering the first level
+const gameSceneSteps = 2
+
+// preloadAssets loads every image and sound the game uses up front, with the
+// enabled mods' versions of them, so that the loading screen can show how far
+// along it is and the scenes find them ready in the caches. Files that fail
+// are left out of the caches and are only reported once something tries to
+// use them, and files that are already cached were counted when they were
+// loaded so they're skipped.
+func preloadAssets(state *LoadingState) {
+	var images, sounds []string
+	for _, name := range preloadImages {
+		if imageCache[name] == nil {
+			images = append(images, name)
+		}
+	}
+	for _, name := range preloadSounds {
+		if soundCache[name] == nil {
+			sounds = append(sounds, name)
+		}
+	}
+	state.Expect(len(images) + len(sounds) + gameSceneSteps)
+
+	for _, name := range images {
+		state.Loading(name)
+		if image, err := asset.Image(assetFS, name); err == nil {
+			imageCache[name] = image
+			loadReport.Add(name, nil)
+		} else {
+			log.Printf("not preloading %s: %v\n", name, err)
+		}
+		state.Done()
+	}
+
+	for _, name := range sounds {
+		state.Loading(name)
+		if data, err := asset.Sound(assetFS, name); err == nil {
+			soundCache[name] = data
+			loadReport.Add(name, nil)
+		} else {
+			log.Printf("not preloading %s: %v\n", name, err)
+		}
+		state.Done()
+	}
+}
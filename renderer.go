@@ -5,10 +5,12 @@ package main
 
 import (
 	"image"
+	"math"
 	"path"
 	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/project-scale/camera"
 	"github.com/solarlune/ldtkgo"
 
 	_ "image/png" // Importing for loading PNGs
@@ -29,9 +31,77 @@ func (l *EmbedLoader) LoadTileset(tileSetPath string) *ebiten.Image {
 	return loadImage(path.Join(l.BasePath, tileSetPath))
 }
 
-// RenderedLayer represents an LDtk.Layer that was rendered out to an *ebiten.Image.
+// How tall each chunk of a rendered level is, small enough for any GPU
+const chunkHeight = 1024
+
+// ChunkedImage is an image as tall as a whole level split into chunks on top
+// of each other, so that tall levels don't need textures larger than the GPU
+// supports and only the chunks in view are drawn
+type ChunkedImage struct {
+	Width, Height int
+	ChunkHeight   int
+	Chunks        []*ebiten.Image // From the top down
+}
+
+// NewChunkedImage creates an empty image of the given size out of chunks of
+// the given height, the bottom one is only as tall as needed
+func NewChunkedImage(width, height, chunkHeight int) *ChunkedImage {
+	c := &ChunkedImage{Width: width, Height: height, ChunkHeight: chunkHeight}
+	for y := 0; y < height; y += chunkHeight {
+		c.Chunks = append(c.Chunks, ebiten.NewImage(width, min(chunkHeight, height-y)))
+	}
+	return c
+}
+
+// DrawImage draws an image onto every chunk it overlaps, as if the chunks
+// were one image
+func (c *ChunkedImage) DrawImage(img *ebiten.Image, opt *ebiten.DrawImageOptions) {
+	bounds := img.Bounds()
+	top, bottom := math.Inf(1), math.Inf(-1)
+	for _, corner := range []image.Point{bounds.Min, bounds.Max, {bounds.Min.X, bounds.Max.Y}, {bounds.Max.X, bounds.Min.Y}} {
+		_, y := opt.GeoM.Apply(float64(corner.X-bounds.Min.X), float64(corner.Y-bounds.Min.Y))
+		top, bottom = math.Min(top, y), math.Max(bottom, y)
+	}
+
+	first := max(0, int(top)/c.ChunkHeight)
+	last := min(len(c.Chunks)-1, int(math.Ceil(bottom))/c.ChunkHeight)
+	for i := first; i <= last; i++ {
+		chunkOpt := *opt
+		chunkOpt.GeoM.Translate(0, -float64(i*c.ChunkHeight))
+		c.Chunks[i].DrawImage(img, &chunkOpt)
+	}
+}
+
+// DrawChunked draws another chunked image of the same size on top of this one
+func (c *ChunkedImage) DrawChunked(other *ChunkedImage) {
+	for i, chunk := range other.Chunks {
+		c.Chunks[i].DrawImage(chunk, &ebiten.DrawImageOptions{})
+	}
+}
+
+// Draw draws the chunks that are in view of the camera
+func (c *ChunkedImage) Draw(cam *camera.Camera) {
+	viewHeight := float64(cam.Surface.Bounds().Dy())
+	top, bottom := cam.Y-viewHeight/2, cam.Y+viewHeight/2
+	for i, chunk := range c.Chunks {
+		y := float64(i * c.ChunkHeight)
+		if y+float64(chunk.Bounds().Dy()) < top || y > bottom {
+			continue
+		}
+		cam.Surface.DrawImage(chunk, cam.GetTranslation(&ebiten.DrawImageOptions{}, 0, y))
+	}
+}
+
+// Dispose frees the images of all the chunks
+func (c *ChunkedImage) Dispose() {
+	for _, chunk := range c.Chunks {
+		chunk.Dispose()
+	}
+}
+
+// RenderedLayer represents an LDtk.Layer that was rendered out to chunks of *ebiten.Images.
 type RenderedLayer struct {
-	Image *ebiten.Image // The image that was rendered out
+	Image *ChunkedImage // The image that was rendered out
 	Layer *ldtkgo.Layer // The layer used to render the image
 }
 
@@ -40,6 +110,7 @@ type TileRenderer struct {
 	Tilesets       map[string]*ebiten.Image
 	CurrentTileset string
 	RenderedLayers []*RenderedLayer
+	ChunkHeight    int           // Height of the chunks layers are rendered in
 	Loader         TilesetLoader // Loader for the renderer; defaults to a DiskLoader instance, though this can be switched out with something else as necessary.
 }
 
@@ -48,6 +119,7 @@ func NewTileRenderer(loader TilesetLoader) *TileRenderer {
 	return &TileRenderer{
 		Tilesets:       map[string]*ebiten.Image{},
 		RenderedLayers: []*RenderedLayer{},
+		ChunkHeight:    chunkHeight,
 		Loader:         loader,
 	}
 }
@@ -71,7 +143,7 @@ func (er *TileRenderer) beginLayer(layer *ldtkgo.Layer, w, h int) {
 
 	er.CurrentTileset = layer.Tileset.Path
 
-	renderedImage := ebiten.NewImage(w, h)
+	renderedImage := NewChunkedImage(w, h, er.ChunkHeight)
 
 	er.RenderedLayers = append(er.RenderedLayers, &RenderedLayer{Image: renderedImage, Layer: layer})
 
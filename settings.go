@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/project-scale/sim"
+)
+
+// Settings are the player's preferences, saved between games
+type Settings struct {
+	MasterVolume int // Percent
+	MusicVolume  int // Percent
+	SFXVolume    int // Percent
+	VoiceVolume  int // Percent
+	MusicMuted   bool
+	SFXMuted     bool
+	VoiceMuted   bool
+	Fullscreen   bool
+	WindowScale  int // How many screen pixels wide a game pixel is in a window
+	ScreenShake  bool
+	VSync        bool
+	Difficulty   string   // Name of the difficulty new rounds are played on
+	DisabledMods []string // Folder names of the mods that are turned off
+}
+
+// Limits of the window scale setting
+const minWindowScale, maxWindowScale = 1, 6
+
+// settings are used by everything in the game that can be set up
+var settings = DefaultSettings()
+
+// DefaultSettings are the settings before the player changes anything
+func DefaultSettings() *Settings {
+	return &Settings{
+		MasterVolume: 100,
+		MusicVolume:  100,
+		SFXVolume:    100,
+		VoiceVolume:  100,
+		WindowScale:  4,
+		ScreenShake:  true,
+		VSync:        true,
+		Difficulty:   sim.DifficultyNormal.Name,
+	}
+}
+
+// Apply changes the mixer and the window to match the settings
+func (s *Settings) Apply() {
+	mixer.Master = float64(s.MasterVolume) / 100
+	mixer.Buses[busMusic].Gain = float64(s.MusicVolume) / 100
+	mixer.Buses[busMusic].Muted = s.MusicMuted
+	mixer.Buses[busSFX].Gain = float64(s.SFXVolume) / 100
+	mixer.Buses[busSFX].Muted = s.SFXMuted
+	mixer.Buses[busVoice].Gain = float64(s.VoiceVolume) / 100
+	mixer.Buses[busVoice].Muted = s.VoiceMuted
+
+	ebiten.SetWindowSize(gameWidth*s.WindowScale, gameHeight*s.WindowScale)
+	ebiten.SetFullscreen(s.Fullscreen)
+	ebiten.SetVsyncEnabled(s.VSync)
+}
+
+func (s *Settings) Load() {
+	m, err := openGameData()
+	if err != nil {
+		return
+	}
+
+	loadInt := func(key string, value *int, min, max int) {
+		if result, err := m.LoadItem("Settings." + key); err == nil {
+			if v, err := strconv.Atoi(string(result)); err == nil && v >= min && v <= max {
+				*value = v
+			}
+		}
+	}
+	loadBool := func(key string, value *bool) {
+		if result, err := m.LoadItem("Settings." + key); err == nil {
+			if v, err := strconv.ParseBool(string(result)); err == nil {
+				*value = v
+			}
+		}
+	}
+
+	loadInt("MasterVolume", &s.MasterVolume, 0, 100)
+	loadInt("MusicVolume", &s.MusicVolume, 0, 100)
+	loadInt("SFXVolume", &s.SFXVolume, 0, 100)
+	loadInt("VoiceVolume", &s.VoiceVolume, 0, 100)
+	loadBool("MusicMuted", &s.MusicMuted)
+	loadBool("SFXMuted", &s.SFXMuted)
+	loadBool("VoiceMuted", &s.VoiceMuted)
+	loadBool("Fullscreen", &s.Fullscreen)
+	loadInt("WindowScale", &s.WindowScale, minWindowScale, maxWindowScale)
+	loadBool("ScreenShake", &s.ScreenShake)
+	loadBool("VSync", &s.VSync)
+	if result, err := m.LoadItem("Settings.Difficulty"); err == nil {
+		s.Difficulty = string(result)
+	}
+	if result, err := m.LoadItem("Settings.DisabledMods"); err == nil && len(result) > 0 {
+		s.DisabledMods = strings.Split(string(result), "\n")
+	}
+}
+
+func (s *Settings) Save() {
+	m, err := openGameData()
+	if err != nil {
+		return
+	}
+
+	m.SaveItem("Settings.MasterVolume", []byte(strconv.Itoa(s.MasterVolume)))
+	m.SaveItem("Settings.MusicVolume", []byte(strconv.Itoa(s.MusicVolume)))
+	m.SaveItem("Settings.SFXVolume", []byte(strconv.Itoa(s.SFXVolume)))
+	m.SaveItem("Settings.VoiceVolume", []byte(strconv.Itoa(s.VoiceVolume)))
+	m.SaveItem("Settings.MusicMuted", []byte(strconv.FormatBool(s.MusicMuted)))
+	m.SaveItem("Settings.SFXMuted", []byte(strconv.FormatBool(s.SFXMuted)))
+	m.SaveItem("Settings.VoiceMuted", []byte(strconv.FormatBool(s.VoiceMuted)))
+	m.SaveItem("Settings.Fullscreen", []byte(strconv.FormatBool(s.Fullscreen)))
+	m.SaveItem("Settings.WindowScale", []byte(strconv.Itoa(s.WindowScale)))
+	m.SaveItem("Settings.ScreenShake", []byte(strconv.FormatBool(s.ScreenShake)))
+	m.SaveItem("Settings.VSync", []byte(strconv.FormatBool(s.VSync)))
+	m.SaveItem("Settings.Difficulty", []byte(s.Difficulty))
+	m.SaveItem("Settings.DisabledMods", []byte(strings.Join(s.DisabledMods, "\n")))
+}
@@ -0,0 +1,50 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package sim
+
+import "encoding/json"
+
+// AnimationSkipTicks sets how many ticks to skip before stepping to the next
+// frame in the animation
+const AnimationSkipTicks = 6
+
+// FrameTag contains tag data about frames to identify different parts of an
+// animation, e.g. idle animation, jump animation frames etc.
+type FrameTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+}
+
+// ParseFrameTags reads the frame tags out of an aseprite-exported sprite JSON,
+// the Nanobot's animations drive some of its state changes so the simulation
+// needs them even when nothing is drawn
+func ParseFrameTags(data []byte) ([]FrameTag, error) {
+	var sprite struct {
+		Meta struct {
+			FrameTags []FrameTag `json:"frameTags"`
+		} `json:"meta"`
+	}
+	err := json.Unmarshal(data, &sprite)
+	return sprite.Meta.FrameTags, err
+}
+
+// Animate determines the next animation frame for a sprite
+func Animate(frame, tick int, ft FrameTag) int {
+	from, to := ft.From, ft.To
+
+	// Instantly start animation if state changed
+	if frame < from || frame >= to {
+		return from
+	}
+
+	// Update only in every 5th cycle
+	if tick%AnimationSkipTicks != 0 {
+		return frame
+	}
+
+	// Continuously increase the Frame counter between from and to
+	return frame + 1
+}
@@ -0,0 +1,55 @@
+package sim
+
+import (
+	"github.com/solarlune/ldtkgo"
+	"github.com/solarlune/resolv"
+)
+
+// Default distance in tiles the water is lowered to below a checkpoint if the
+// level designer didn't set one
+const checkpointSafeHeight = 8
+
+// Checkpoint is a place in the level where the player respawns after dying
+// instead of restarting the whole climb
+type Checkpoint struct {
+	*resolv.Object
+	SafeHeight float64 // How far below the checkpoint the water is on respawn
+	Reached    bool    // Whether the player touched it this round
+}
+
+// NewCheckpoint creates a checkpoint and its collision object from an LDtk
+// entity
+func NewCheckpoint(entity *ldtkgo.Entity) *Checkpoint {
+	object := resolv.NewObject(
+		float64(entity.Position[0]), float64(entity.Position[1]),
+		float64(entity.Width), float64(entity.Height),
+		TagCheckpoint,
+	)
+	object.SetShape(resolv.NewRectangle(
+		0, 0, // origin
+		float64(entity.Width), float64(entity.Height),
+	))
+
+	safeHeight := checkpointSafeHeight
+	if prop := entity.PropertyByIdentifier("Safe_height"); prop != nil && !prop.IsNull() {
+		safeHeight = prop.AsInt()
+	}
+
+	c := &Checkpoint{
+		Object:     object,
+		SafeHeight: float64(safeHeight * GridSize),
+	}
+	object.Data = c
+	return c
+}
+
+// Center returns the position the player respawns at, the centre of the
+// checkpoint just like the start position is the centre of Player_start
+func (c *Checkpoint) Center() (float64, float64) {
+	return c.Position.X + c.Size.X/2, c.Position.Y + c.Size.Y/2
+}
+
+// WaterLevel returns the level the water is lowered to on respawn
+func (c *Checkpoint) WaterLevel() float64 {
+	return c.Position.Y + c.SafeHeight
+}
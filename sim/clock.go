@@ -0,0 +1,79 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package sim
+
+import "time"
+
+// TPS is how many times per second the simulation is stepped
+const TPS = 60
+
+// Clock tells the simulation what time it is
+type Clock interface {
+	Now() time.Time
+}
+
+// Stepper is a Clock that needs to be told when a tick has passed
+type Stepper interface {
+	Step()
+}
+
+// WallClock is the real time of the computer the game runs on
+type WallClock struct{}
+
+// Now returns the current local time
+func (WallClock) Now() time.Time {
+	return time.Now()
+}
+
+// TickClock only advances when the simulation is stepped, exactly one tick
+// each time, so the same inputs always give the same round times
+type TickClock struct {
+	Time time.Time
+}
+
+// Now returns the time after all the ticks stepped so far
+func (c *TickClock) Now() time.Time {
+	return c.Time
+}
+
+// Step advances the clock by one tick
+func (c *TickClock) Step() {
+	c.Time = c.Time.Add(time.Second / TPS)
+}
+
+// Pauser is a Clock that can be stopped while the game isn't being played
+type Pauser interface {
+	Pause()
+	Resume()
+}
+
+// GameClock is the real time spent playing, it doesn't count the time it's
+// paused for, like while the pause menu is open
+type GameClock struct {
+	Offset   time.Duration // How long it's been paused for altogether
+	PausedAt time.Time     // When it was paused, zero if it's running
+}
+
+// Now returns the current local time, minus the time spent paused
+func (c *GameClock) Now() time.Time {
+	if !c.PausedAt.IsZero() {
+		return c.PausedAt.Add(-c.Offset)
+	}
+	return time.Now().Add(-c.Offset)
+}
+
+// Pause stops the clock until it's resumed
+func (c *GameClock) Pause() {
+	if c.PausedAt.IsZero() {
+		c.PausedAt = time.Now()
+	}
+}
+
+// Resume starts the clock again from where it was paused
+func (c *GameClock) Resume() {
+	if !c.PausedAt.IsZero() {
+		c.Offset += time.Since(c.PausedAt)
+		c.PausedAt = time.Time{}
+	}
+}
@@ -0,0 +1,111 @@
+package sim
+
+import (
+	"errors"
+	"math"
+)
+
+// Difficulty is how fast the water rises and how quickly and far the player
+// moves, the same level plays very differently depending on it
+type Difficulty struct {
+	Name          string
+	WaterSpeed    float64     // Pixels the water rises every tick at the start of a round
+	WaterAccel    float64     // How much faster the water rises every minute, in pixels per tick
+	WaterMaxSpeed float64     // Fastest the water ever rises, no limit if 0
+	WaterBands    []WaterBand // Speed-ups once the water has risen far enough, lowest first
+	ClimbSpeed    float64     // Pixels per tick
+	JumpSpeed     float64     // Pixels per tick
+	MinJumpDist   float64     // Pixels a jump goes at least
+	MaxJumpDist   float64     // Pixels a jump goes at most
+}
+
+// WaterBand makes the water rise faster once it's above a height
+type WaterBand struct {
+	Height float64 // Pixels above where the water starts
+	Factor float64 // How many times faster it rises from there
+}
+
+var DifficultyEasy = Difficulty{
+	Name:        "Easy",
+	WaterSpeed:  0.25,
+	ClimbSpeed:  1.4,
+	JumpSpeed:   DifficultyNormal.JumpSpeed,
+	MinJumpDist: DifficultyNormal.MinJumpDist,
+	MaxJumpDist: DifficultyNormal.MaxJumpDist + GridSize, // Clears one more tile
+}
+
+var DifficultyNormal = Difficulty{
+	Name:       "Normal",
+	WaterSpeed: WaterSpeed,
+	ClimbSpeed: 1.2,
+	JumpSpeed:  4.0,
+	// I it's because 4 is the distance from the player sprite origin to the
+	// collision object or maybe it's because 4 is the current jump movement
+	// distance and a fencepost error means it has already moved once by 4
+	// before the check happens, either way 4 is the value that seems to take
+	// you the right distance to the next tile in practice
+	MinJumpDist: 32 - 4,
+	MaxJumpDist: 64 - 4,
+}
+
+var DifficultyHard = Difficulty{
+	Name:          "Hard",
+	WaterSpeed:    WaterSpeed,
+	WaterAccel:    0.1,
+	WaterMaxSpeed: 0.7,
+	WaterBands: []WaterBand{
+		{Height: 1600, Factor: 1.25},
+	},
+	ClimbSpeed:  1.0,
+	JumpSpeed:   DifficultyNormal.JumpSpeed,
+	MinJumpDist: DifficultyNormal.MinJumpDist,
+	MaxJumpDist: DifficultyNormal.MaxJumpDist - GridSize, // Clears one tile less
+}
+
+// Difficulties are the preset difficulties in the order they're offered
+var Difficulties = []*Difficulty{
+	&DifficultyEasy,
+	&DifficultyNormal,
+	&DifficultyHard,
+}
+
+// DifficultyByName looks up a preset difficulty, nil if there isn't one
+// called that
+func DifficultyByName(name string) *Difficulty {
+	for _, d := range Difficulties {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// WaterSpeedAt is how fast the water rises after it's been rising for the
+// given ticks and has risen the given pixels
+func (d *Difficulty) WaterSpeedAt(ticks int, risen float64) float64 {
+	factor := 1.0
+	for _, band := range d.WaterBands {
+		if risen < band.Height {
+			break
+		}
+		factor = band.Factor
+	}
+	speed := (d.WaterSpeed + d.WaterAccel*float64(ticks)/(60*60)) * factor
+	if d.WaterMaxSpeed > 0 {
+		speed = math.Min(speed, d.WaterMaxSpeed)
+	}
+	return speed
+}
+
+// Validate reports a difficulty the player couldn't move with
+func (d *Difficulty) Validate() error {
+	switch {
+	case d.WaterSpeed < 0 || d.WaterMaxSpeed < 0:
+		return errors.New("the water can't sink")
+	case d.ClimbSpeed <= 0 || d.JumpSpeed <= 0:
+		return errors.New("the player has to move")
+	case d.MinJumpDist <= 0 || d.MaxJumpDist < d.MinJumpDist:
+		return errors.New("jumps have to go somewhere and the longest can't be shorter than the shortest")
+	}
+	return nil
+}
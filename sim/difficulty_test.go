@@ -0,0 +1,70 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWaterSpeedAt(t *testing.T) {
+	banded := &Difficulty{
+		WaterSpeed: 0.5,
+		WaterBands: []WaterBand{{Height: 100, Factor: 2}, {Height: 200, Factor: 3}},
+	}
+	minute := 60 * 60
+	for _, tc := range []struct {
+		name       string
+		difficulty *Difficulty
+		ticks      int
+		risen      float64
+		want       float64
+	}{
+		{"normal at the start", &DifficultyNormal, 0, 0, WaterSpeed},
+		{"normal later on", &DifficultyNormal, 10 * minute, 3000, WaterSpeed},
+		{"easy", &DifficultyEasy, 10 * minute, 3000, 0.25},
+		{"hard at the start", &DifficultyHard, 0, 0, WaterSpeed},
+		{"hard after a minute", &DifficultyHard, minute, 0, WaterSpeed + 0.1},
+		{"hard at its fastest", &DifficultyHard, 10 * minute, 0, 0.7},
+		{"hard above its band", &DifficultyHard, 0, 1600, WaterSpeed * 1.25},
+		{"below the bands", banded, 0, 99, 0.5},
+		{"in the first band", banded, 0, 150, 1},
+		{"in the second band", banded, 0, 200, 1.5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.difficulty.WaterSpeedAt(tc.ticks, tc.risen); math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("water rises at %.4f after %d ticks and %.0f pixels, want %.4f", got, tc.ticks, tc.risen, tc.want)
+			}
+		})
+	}
+}
+
+func TestDifficultyValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		change func(d *Difficulty)
+		ok     bool
+	}{
+		{"normal", func(d *Difficulty) {}, true},
+		{"still water", func(d *Difficulty) { d.WaterSpeed = 0 }, true},
+		{"sinking water", func(d *Difficulty) { d.WaterSpeed = -0.1 }, false},
+		{"sinking at most", func(d *Difficulty) { d.WaterMaxSpeed = -1 }, false},
+		{"no climbing", func(d *Difficulty) { d.ClimbSpeed = 0 }, false},
+		{"no jumping", func(d *Difficulty) { d.JumpSpeed = 0 }, false},
+		{"jumps nowhere", func(d *Difficulty) { d.MinJumpDist = 0 }, false},
+		{"longest jump too short", func(d *Difficulty) { d.MaxJumpDist = d.MinJumpDist - 1 }, false},
+		{"jumps all the same", func(d *Difficulty) { d.MaxJumpDist = d.MinJumpDist }, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := DifficultyNormal
+			tc.change(&d)
+			if err := d.Validate(); (err == nil) != tc.ok {
+				t.Errorf("Validate() = %v, want valid %v", err, tc.ok)
+			}
+		})
+	}
+
+	for _, d := range Difficulties {
+		if err := d.Validate(); err != nil {
+			t.Errorf("preset difficulty %s isn't valid: %v", d.Name, err)
+		}
+	}
+}
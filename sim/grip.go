@@ -0,0 +1,43 @@
+package sim
+
+// Grip is how much longer the player can hold on for, it runs out while
+// climbing or hanging on and comes back while standing on top of a wall
+const (
+	MaxGrip       = 1.0
+	GripLow       = MaxGrip / 4         // Below this the player is warned
+	gripDrain     = MaxGrip / (60 * 60) // A minute of climbing
+	gripDrainHard = gripDrain * 4       // Hard grip tiles are four times as tiring
+	gripRecover   = MaxGrip / (3 * 60)  // Three seconds of standing
+)
+
+// updateGrip uses up or recovers grip depending on what the player is doing,
+// once it runs out they let go
+func (p *Player) updateGrip() {
+	switch p.AnimState {
+	case PlayerIdle, PlayerClimb:
+		if p.onHardGrip() {
+			p.Grip -= gripDrainHard
+		} else {
+			p.Grip -= gripDrain
+		}
+		if p.Grip <= 0 {
+			p.Grip = 0
+			p.AnimState = PlayerNogrip
+			p.SpeedX, p.SpeedY = 0, 0
+		}
+	case PlayerStand, PlayerWalkleft, PlayerWalkright:
+		p.Grip = min(p.Grip+gripRecover, MaxGrip)
+	}
+}
+
+// onHardGrip reports whether the player is holding on to a hard grip tile
+func (p *Player) onHardGrip() bool {
+	if collision := p.Check(0, 0, TagHardGrip); collision != nil {
+		for _, o := range collision.Objects {
+			if centreInside(o, p.Position.X, p.Position.Y, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// gripLevel has an ordinary climbable tile, a hard grip one and a wall to
+// stand on
+var gripLevel = []string{
+	"#F##",
+	"#..#",
+	"#h.#",
+	"#...",
+	"#.##",
+	"#S.#",
+	"#..#",
+	"#..#",
+}
+
+func TestGrip(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		x, y     int // Tile the player is on
+		standing bool
+		ticks    int
+		grip     float64 // Grip to start with
+		want     float64
+	}{
+		{"hanging on", 1, 3, false, 60, MaxGrip, MaxGrip - 60*gripDrain},
+		{"hanging on hard grip", 1, 2, false, 60, MaxGrip, MaxGrip - 60*gripDrainHard},
+		{"standing", 2, 3, true, 60, GripLow, GripLow + 60*gripRecover},
+		{"standing until full", 2, 3, true, 3 * 60, GripLow, MaxGrip},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newTestWorld(t, &script{}, gripLevel...)
+			placeOn(w, tc.x, tc.y)
+			if tc.standing {
+				w.Player.State, w.Player.AnimState = StateStanding, PlayerStand
+			}
+			w.Player.Grip = tc.grip
+			events := run(w, tc.ticks)
+			if math.Abs(w.Player.Grip-tc.want) > 1e-9 {
+				t.Errorf("grip %.4f after %d ticks, want %.4f", w.Player.Grip, tc.ticks, tc.want)
+			}
+			if slices.Contains(events, EventNoGrip) {
+				t.Errorf("ran out of grip: %v", events)
+			}
+		})
+	}
+}
+
+func TestGripRunsOut(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		x, y  int
+		ticks int // Until it runs out
+	}{
+		{"ordinary", 1, 3, 10},
+		{"hard grip", 1, 2, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newTestWorld(t, &script{}, gripLevel...)
+			placeOn(w, tc.x, tc.y)
+			w.Player.Grip = 10 * gripDrain
+			if ticks := runUntil(t, w, EventNoGrip, 20); ticks != tc.ticks {
+				t.Errorf("ran out of grip after %d ticks, want %d", ticks, tc.ticks)
+			}
+			if w.Player.Grip != 0 {
+				t.Errorf("grip %.4f after running out, want 0", w.Player.Grip)
+			}
+
+			// The player lets go once the animation is over
+			run(w, animationTicks(w.Player.FrameTags, PlayerNogrip))
+			if w.Player.State != StateFalling {
+				t.Errorf("player is %s after letting go, want Falling", PlayerStateNames[w.Player.State])
+			}
+		})
+	}
+}
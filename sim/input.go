@@ -0,0 +1,28 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+// Package sim is the game's simulation: climbing, jumping, grappling, the
+// rising water and reaching the finish. It doesn't know anything about the
+// screen, speakers or input devices so it can be stepped one tick at a time
+// without a display, e.g. in tests.
+package sim
+
+// Action is something the player can ask the Nanobot to do
+type Action uint8
+
+// The actions the simulation responds to
+const (
+	ActionMoveUp Action = iota
+	ActionMoveLeft
+	ActionMoveDown
+	ActionMoveRight
+	ActionPrimary
+	ActionMenu
+	ActionSecondary
+)
+
+// Input is where the simulation reads the player's actions from each tick
+type Input interface {
+	ActionIsPressed(action Action) bool
+	ActionIsJustPressed(action Action) bool
+}
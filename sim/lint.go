@@ -0,0 +1,68 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/solarlune/ldtkgo"
+)
+
+// RequiredLayers are the layers every level needs, even if they're empty
+var RequiredLayers = []string{LayerEntities, LayerFloor, LayerWalls, LayerInvisible}
+
+// ReadProject parses an LDtk project the same way for the game and the tools
+func ReadProject(r io.Reader) (*ldtkgo.Project, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ldtkgo.Read(data)
+}
+
+// CheckLayers reports which of the required layers a level is missing
+func CheckLayers(level *ldtkgo.Level) error {
+	var errs []error
+	for _, name := range RequiredLayers {
+		if level.LayerByIdentifier(name) == nil {
+			errs = append(errs, fmt.Errorf("level %s has no %s layer", level.Identifier, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LintLevel lists everything wrong with a level that would stop it from
+// loading or from being finished, it returns nothing for a level that's ready
+// to ship. The route to the finish is returned too, timed using the Nanobot's
+// animations from the frame tags if they're given and moving as the difficulty
+// allows
+func LintLevel(level *ldtkgo.Level, frameTags []FrameTag, difficulty *Difficulty) (*Route, []error) {
+	var problems []error
+	if err := CheckLayers(level); err != nil {
+		problems = append(problems, err)
+	}
+	if entities := level.LayerByIdentifier(LayerEntities); entities != nil {
+		for _, name := range []string{EntityPlayerStart, EntityFinish} {
+			if entities.EntityByIdentifier(name) == nil {
+				problems = append(problems, fmt.Errorf("level %s has no %s entity", level.Identifier, name))
+			}
+		}
+	}
+	if err := ValidateLevel(level); err != nil {
+		problems = append(problems, err)
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	w, err := NewWorld(level, frameTags, WallClock{})
+	if err != nil {
+		return nil, []error{err}
+	}
+	w.SetDifficulty(difficulty)
+	route, ok := w.Solve()
+	if !ok {
+		problems = append(problems, fmt.Errorf("level %s has no way to climb from the %s to the %s on %s", level.Identifier, EntityPlayerStart, EntityFinish, difficulty.Name))
+	}
+	return route, problems
+}
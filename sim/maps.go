@@ -0,0 +1,175 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/solarlune/ldtkgo"
+	"github.com/solarlune/resolv"
+)
+
+// A list of map tile tag names
+const (
+	TagClimbable  = "climbable"
+	TagWall       = "wall"
+	TagChasm      = "chasm"
+	TagSlippery   = "slippery"
+	TagFinish     = "finish"
+	TagDecor      = "decoration"
+	TagCheckpoint = "checkpoint"
+	TagPlatform   = "platform" // Moving platforms and crumbling tiles, which hold the player up over chasms
+	TagHardGrip   = "hardgrip" // Climbable tiles that use up grip faster
+	TagTrigger    = "trigger"  // Places that make the water surge
+)
+
+// TileKindEnum is the LDtk enum the tileset is tagged with to say how each
+// tile behaves
+const TileKindEnum = "TileKind"
+
+// TileBehaviours is a lookup table from the TileKind enum values a tile can be
+// tagged with in LDtk to the tag of its collision object. Values named like
+// "Slippery_Ice" behave like the part before the underscore, so new kinds of
+// tile only need a value added to the enum in LDtk
+var TileBehaviours = map[string]string{
+	"Climbable":  TagClimbable,
+	"Wall":       TagWall,
+	"Chasm":      TagChasm,
+	"Slippery":   TagSlippery,
+	"Decoration": TagDecor,
+}
+
+// TileVariants is a lookup table from the part of a TileKind enum value after
+// the underscore to an extra tag for tiles that behave slightly differently,
+// like "Climbable_Hard"
+var TileVariants = map[string]string{
+	"Hard": TagHardGrip,
+}
+
+// TileTags returns the tags of a tile from the TileKind enum value it's tagged
+// with in the tileset, its behaviour first and then its variant if it has one,
+// false if it has none or more than one
+func TileTags(tileset *ldtkgo.Tileset, id int) ([]string, bool) {
+	var tags []string
+	for _, kind := range tileset.EnumsForTile(id) {
+		base, variant, _ := strings.Cut(kind, "_")
+		behaviour, ok := TileBehaviours[base]
+		if !ok || tags != nil {
+			return nil, false
+		}
+		tags = []string{behaviour}
+		if tag, ok := TileVariants[variant]; ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, tags != nil
+}
+
+// TilesToObstacles adds an object to the collision space for every tile in the
+// layer, tagged by what kind of tile it is. Tiles without a known kind are
+// left out and listed in the error
+func TilesToObstacles(layer *ldtkgo.Layer, space *resolv.Space) error {
+	var unknown []int
+	for _, tileData := range layer.AllTiles() {
+		tags, ok := TileTags(layer.Tileset, tileData.ID)
+		if !ok {
+			unknown = append(unknown, tileData.ID)
+			continue
+		}
+
+		size := float64(layer.Tileset.GridSize)
+		x, y := tileData.Position[0], tileData.Position[1]
+
+		object := resolv.NewObject(
+			float64(x+layer.OffsetX), float64(y+layer.OffsetY),
+			size, size,
+			tags...,
+		)
+		object.SetShape(resolv.NewRectangle(
+			0, 0, // origin
+			size, size,
+		))
+
+		space.Add(object)
+	}
+	return unknownTilesError(layer, unknown)
+}
+
+// unknownTilesError describes the tiles of a layer that aren't tagged with
+// exactly one known TileKind, nil if there are none
+func unknownTilesError(layer *ldtkgo.Layer, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = strconv.Itoa(id)
+		if kinds := layer.Tileset.EnumsForTile(id); len(kinds) > 0 {
+			names[i] += " (" + strings.Join(kinds, ", ") + ")"
+		}
+	}
+	known := slices.Sorted(maps.Keys(TileBehaviours))
+	return fmt.Errorf("layer %s uses tiles of tileset %s without exactly one %s tag (%s): %s",
+		layer.Identifier, layer.Tileset.Identifier, TileKindEnum, strings.Join(known, ", "), strings.Join(names, ", "))
+}
+
+// ValidateLevel checks that every tile the game collides with has a known
+// behaviour, and reports all the tiles that don't, and that the water curve
+// in the level's fields makes sense
+func ValidateLevel(level *ldtkgo.Level) error {
+	var errs []error
+	for _, name := range []string{LayerFloor, LayerWalls, LayerInvisible} {
+		layer := level.LayerByIdentifier(name)
+		if layer == nil || layer.Tileset == nil {
+			continue
+		}
+		var unknown []int
+		for _, tileData := range layer.AllTiles() {
+			if _, ok := TileTags(layer.Tileset, tileData.ID); !ok {
+				unknown = append(unknown, tileData.ID)
+			}
+		}
+		if err := unknownTilesError(layer, unknown); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := ReadWaterCurve(level, level.Height); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("level %s: %w", level.Identifier, err)
+	}
+	return nil
+}
+
+const (
+	EntityPlayerStart = "Player_start"
+	EntityFinish      = "Finish"
+	EntityCheckpoint  = "Checkpoint"
+	EntityPlatform    = "Moving_platform"
+	EntityCrumbling   = "Crumbling_tile"
+	EntityTrigger     = "Water_trigger"
+	EntitySplit       = "Split"
+)
+
+const (
+	LayerEntities  = "Entities"
+	LayerFloor     = "Floor"
+	LayerWalls     = "Walls"
+	LayerInvisible = "Invisible"
+)
+
+// IsPlayable reports whether a level has everything needed to be part of the
+// campaign, levels still being worked on in LDtk might not
+func IsPlayable(level *ldtkgo.Level) bool {
+	entities := level.LayerByIdentifier(LayerEntities)
+	return entities != nil &&
+		entities.EntityByIdentifier(EntityPlayerStart) != nil &&
+		entities.EntityByIdentifier(EntityFinish) != nil
+}
@@ -0,0 +1,217 @@
+package sim
+
+import (
+	"github.com/quartercastle/vector"
+	"github.com/solarlune/ldtkgo"
+	"github.com/solarlune/resolv"
+)
+
+// Default speed in pixels per tick of a moving platform if the level designer
+// didn't set one
+const platformSpeed = 0.5
+
+// Default ticks a crumbling tile holds the player for if the level designer
+// didn't set it
+const crumbleDelay = 60
+
+// Platform is a climbable block that moves back and forth along a path,
+// carrying the player along while they hold on to it
+type Platform struct {
+	*resolv.Object
+	Path   []vector.Vector // Positions of its top-left corner it moves between, starting where it's placed
+	Speed  float64         // Pixels per tick
+	Loop   bool            // Goes back to the start after the end of the path instead of turning around
+	Target int             // Index of the point in the path it's moving to
+	Step   int             // Which way along the path it's going, 1 forwards and -1 backwards
+}
+
+// NewPlatform creates a moving platform and its collision object from an LDtk
+// entity, the points of its path are tiles of the level
+func NewPlatform(entity *ldtkgo.Entity) *Platform {
+	object := newEntityObject(entity, TagClimbable, TagPlatform)
+
+	p := &Platform{
+		Object: object,
+		Path:   []vector.Vector{{object.Position.X, object.Position.Y}},
+		Speed:  platformSpeed,
+	}
+	if prop := entity.PropertyByIdentifier("Path"); prop != nil && !prop.IsNull() {
+		for _, point := range prop.AsArray() {
+			if point, ok := point.(map[string]any); ok {
+				cx, _ := point["cx"].(float64)
+				cy, _ := point["cy"].(float64)
+				p.Path = append(p.Path, vector.Vector{cx * GridSize, cy * GridSize})
+			}
+		}
+	}
+	if prop := entity.PropertyByIdentifier("Speed"); prop != nil && !prop.IsNull() {
+		p.Speed = prop.AsFloat64()
+	}
+	if prop := entity.PropertyByIdentifier("Loop"); prop != nil && !prop.IsNull() {
+		p.Loop = prop.AsBool()
+	}
+	object.Data = p
+	p.Reset()
+	return p
+}
+
+// Reset puts the platform back at the start of its path
+func (p *Platform) Reset() {
+	p.Position.X, p.Position.Y = p.Path[0][0], p.Path[0][1]
+	p.Target, p.Step = 0, 1
+	if len(p.Path) > 1 {
+		p.Target = 1
+	}
+	p.Object.Update()
+}
+
+// Update moves the platform one tick along its path and returns how far it
+// moved
+func (p *Platform) Update() vector.Vector {
+	position := vector.Vector{p.Position.X, p.Position.Y}
+	move := p.Path[p.Target].Sub(position)
+	if move.Magnitude() <= p.Speed {
+		p.nextTarget()
+	} else {
+		move = move.Unit().Scale(p.Speed)
+	}
+	p.Position.X += move[0]
+	p.Position.Y += move[1]
+	p.Object.Update()
+	return move
+}
+
+// nextTarget picks the next point of the path to move to once a point has
+// been reached
+func (p *Platform) nextTarget() {
+	if len(p.Path) < 2 {
+		return
+	}
+	switch {
+	case p.Loop:
+		p.Target = (p.Target + 1) % len(p.Path)
+	case p.Target+p.Step < 0 || p.Target+p.Step >= len(p.Path):
+		p.Step = -p.Step
+		fallthrough
+	default:
+		p.Target += p.Step
+	}
+}
+
+// CrumbleState is how far a crumbling tile has fallen apart
+type CrumbleState uint8
+
+const (
+	CrumbleIntact   CrumbleState = iota // Holds the player up
+	CrumbleShaking                      // Was gripped and is about to give way
+	CrumbleCrumbled                     // Gone, a chasm until it comes back
+)
+
+// CrumblingTile is a climbable block that gives way a while after the player
+// grips it and leaves a chasm behind, which may come back later
+type CrumblingTile struct {
+	*resolv.Object
+	Delay   int // Ticks it shakes for before it crumbles
+	Respawn int // Ticks until it comes back after crumbling, never if 0
+	Timer   int // Ticks left until it crumbles or comes back
+	Age     int // Ticks since it last changed state
+	State   CrumbleState
+}
+
+// NewCrumblingTile creates a crumbling tile and its collision object from an
+// LDtk entity
+func NewCrumblingTile(entity *ldtkgo.Entity) *CrumblingTile {
+	c := &CrumblingTile{
+		Object: newEntityObject(entity, TagClimbable, TagPlatform),
+		Delay:  crumbleDelay,
+	}
+	if prop := entity.PropertyByIdentifier("Delay"); prop != nil && !prop.IsNull() {
+		c.Delay = prop.AsInt()
+	}
+	if prop := entity.PropertyByIdentifier("Respawn"); prop != nil && !prop.IsNull() {
+		c.Respawn = prop.AsInt()
+	}
+	c.Object.Data = c
+	return c
+}
+
+// Reset puts the tile back together
+func (c *CrumblingTile) Reset() {
+	c.setState(CrumbleIntact)
+}
+
+// Update starts the tile shaking when the player grips it and makes it crumble
+// or come back when it's time, it reports whether it crumbled this tick
+func (c *CrumblingTile) Update(p *Player) bool {
+	c.Age++
+	switch c.State {
+	case CrumbleIntact:
+		if holds(c.Object, p) {
+			c.setState(CrumbleShaking)
+		}
+	case CrumbleShaking:
+		if c.Timer--; c.Timer <= 0 {
+			c.setState(CrumbleCrumbled)
+			return true
+		}
+	case CrumbleCrumbled:
+		if c.Respawn > 0 {
+			if c.Timer--; c.Timer <= 0 {
+				c.setState(CrumbleIntact)
+			}
+		}
+	}
+	return false
+}
+
+// setState changes what the tile is and how its collision object is tagged,
+// the first tag is what the player reacts to
+func (c *CrumblingTile) setState(state CrumbleState) {
+	c.State = state
+	c.Age = 0
+	switch state {
+	case CrumbleShaking:
+		c.Timer = c.Delay
+	case CrumbleCrumbled:
+		c.Timer = c.Respawn
+		c.RemoveTags(TagClimbable, TagPlatform)
+		c.AddTags(TagChasm, TagPlatform)
+	case CrumbleIntact:
+		c.Timer = 0
+		c.RemoveTags(TagChasm, TagPlatform)
+		c.AddTags(TagClimbable, TagPlatform)
+	}
+}
+
+// holds reports whether the player is holding on to an object, climbing or
+// standing with its centre over it
+func holds(o *resolv.Object, p *Player) bool {
+	switch p.State {
+	case StateIdle, StateStanding:
+	default:
+		return false
+	}
+	return o.HasTags(TagClimbable) && centreInside(o, p.Position.X, p.Position.Y, p)
+}
+
+// centreInside reports whether the centre of the player placed at x, y is
+// inside an object
+func centreInside(o *resolv.Object, x, y float64, p *Player) bool {
+	cx, cy := x+p.Size.X/2, y+p.Size.Y/2
+	return cx >= o.Position.X && cx <= o.Position.X+o.Size.X &&
+		cy >= o.Position.Y && cy <= o.Position.Y+o.Size.Y
+}
+
+// newEntityObject creates a collision object covering an LDtk entity
+func newEntityObject(entity *ldtkgo.Entity, tags ...string) *resolv.Object {
+	object := resolv.NewObject(
+		float64(entity.Position[0]), float64(entity.Position[1]),
+		float64(entity.Width), float64(entity.Height),
+		tags...,
+	)
+	object.SetShape(resolv.NewRectangle(
+		0, 0, // origin
+		float64(entity.Width), float64(entity.Height),
+	))
+	return object
+}
@@ -0,0 +1,136 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quartercastle/vector"
+	"github.com/solarlune/ldtkgo"
+)
+
+func TestPlatformPath(t *testing.T) {
+	// path is an LDtk points field, in tiles
+	path := func(points ...[2]float64) *ldtkgo.Property {
+		var value []any
+		for _, p := range points {
+			value = append(value, map[string]any{"cx": p[0], "cy": p[1]})
+		}
+		return &ldtkgo.Property{Identifier: "Path", Value: value}
+	}
+	speed := &ldtkgo.Property{Identifier: "Speed", Value: 1.0}
+	loop := &ldtkgo.Property{Identifier: "Loop", Value: true}
+
+	for _, tc := range []struct {
+		name       string
+		properties []*ldtkgo.Property
+		ticks      int
+		want       vector.Vector
+		wantTarget int
+	}{
+		{"no path", nil, 100, vector.Vector{0, 0}, 0},
+		{"default speed", []*ldtkgo.Property{path([2]float64{2, 0})}, 10, vector.Vector{5, 0}, 1},
+		{"to the end", []*ldtkgo.Property{path([2]float64{2, 0}), speed}, 32, vector.Vector{32, 0}, 0},
+		{"turned around", []*ldtkgo.Property{path([2]float64{2, 0}), speed}, 40, vector.Vector{24, 0}, 0},
+		{"back at the start", []*ldtkgo.Property{path([2]float64{2, 0}), speed}, 64, vector.Vector{0, 0}, 1},
+		{"along the path", []*ldtkgo.Property{path([2]float64{2, 0}, [2]float64{2, 2}), speed}, 48, vector.Vector{32, 16}, 2},
+		{"back along the path", []*ldtkgo.Property{path([2]float64{2, 0}, [2]float64{2, 2}), speed}, 64, vector.Vector{32, 32}, 1},
+		{"looping", []*ldtkgo.Property{path([2]float64{2, 0}, [2]float64{2, 2}), speed, loop}, 64, vector.Vector{32, 32}, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			platform := NewPlatform(&ldtkgo.Entity{
+				Identifier: EntityPlatform,
+				Position:   []int{0, 0},
+				Width:      GridSize,
+				Height:     GridSize,
+				Properties: tc.properties,
+			})
+			for range tc.ticks {
+				platform.Update()
+			}
+			if got := (vector.Vector{platform.Position.X, platform.Position.Y}); !got.Equal(tc.want) || platform.Target != tc.wantTarget {
+				t.Errorf("platform at %v going to point %d after %d ticks, want at %v going to point %d",
+					got, platform.Target, tc.ticks, tc.want, tc.wantTarget)
+			}
+		})
+	}
+}
+
+func TestPlatformCarriesPlayer(t *testing.T) {
+	level := testLevel(
+		"#####",
+		"#F..#",
+		"#...#",
+		"#P~~#",
+		"#S..#",
+		"#...#",
+		"#...#",
+	)
+	setField(level, EntityPlatform, "Path", []any{map[string]any{"cx": 3.0, "cy": 3.0}})
+	setField(level, EntityPlatform, "Speed", 1.0)
+	w := newLevelWorld(t, &script{}, level)
+	placeOn(w, 1, 3)
+	x, y := w.Player.Position.X, w.Player.Position.Y
+
+	run(w, 20)
+	if w.Player.State != StateIdle || w.Player.Position.X != x+20 || w.Player.Position.Y != y {
+		t.Errorf("player is %s at %.1f, %.1f, want to hold on to the platform at %.1f, %.1f",
+			PlayerStateNames[w.Player.State], w.Player.Position.X, w.Player.Position.Y, x+20, y)
+	}
+
+	// The platform leaves a player who isn't holding on behind
+	w.Reset()
+	placeOn(w, 1, 4)
+	x, y = w.Player.Position.X, w.Player.Position.Y
+	run(w, 20)
+	if w.Player.Position.X != x || w.Player.Position.Y != y {
+		t.Errorf("player moved from %.1f, %.1f to %.1f, %.1f next to the platform", x, y, w.Player.Position.X, w.Player.Position.Y)
+	}
+}
+
+func TestCrumblingTile(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		respawn any
+		intact  bool // After crumbling and waiting for it to come back
+	}{
+		{"gone for good", nil, false},
+		{"comes back", 30.0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			level := testLevel(
+				"#F#",
+				"#.#",
+				"#.#",
+				"#x#",
+				"#S#",
+				"#.#",
+				"#.#",
+			)
+			setField(level, EntityCrumbling, "Delay", 20.0)
+			setField(level, EntityCrumbling, "Respawn", tc.respawn)
+			w := newLevelWorld(t, &script{}, level)
+			tile := w.Crumbling[0]
+			placeOn(w, 1, 3)
+
+			ticks := runUntil(t, w, EventCrumble, 60)
+			if ticks != 21 || tile.State != CrumbleCrumbled {
+				t.Errorf("tile %d crumbled after %d ticks, want after shaking for 20", tile.State, ticks)
+			}
+			w.Step()
+			if w.Player.State != StateFalling {
+				t.Errorf("player is %s on the crumbled tile, want Falling", PlayerStateNames[w.Player.State])
+			}
+
+			placeOn(w, 1, 1) // Out of the way while the tile comes back
+			run(w, 40)
+			if intact := tile.State == CrumbleIntact; intact != tc.intact {
+				t.Errorf("tile is %d a while after crumbling, want intact %v", tile.State, tc.intact)
+			}
+
+			// Starting over puts it back
+			w.Reset()
+			if tile.State != CrumbleIntact || !tile.HasTags(TagClimbable) {
+				t.Errorf("tile is %d with tags %v after a reset, want intact and climbable", tile.State, tile.Tags())
+			}
+		})
+	}
+}
@@ -0,0 +1,568 @@
+package sim
+
+import (
+	"log"
+	"math"
+
+	"github.com/quartercastle/vector"
+	"github.com/solarlune/resolv"
+)
+
+//go:generate ../tools/gen_sprite_tags.sh ../assets/sprites/Nanobot.json player_anim.go Player sim
+
+// GrappleRange is how far the grappling hook can reach to find a climbable
+const GrappleRange = 6 * GridSize
+
+const (
+	speedFall      = 6.0
+	speedSliploop  = 2.0
+	speedSlip      = 0.2
+	speedDeathFall = 0.5
+	speedGrapple   = 5.0
+)
+
+type Direction int8
+
+const (
+	DirectionUp Direction = iota
+	DirectionRight
+	DirectionDown
+	DirectionLeft
+)
+
+type PlayerState int8
+
+const (
+	StateIdle PlayerState = iota
+	StateJumping
+	StateFalling
+	StateSlipping
+	StateStanding
+	StateDying
+	StateDead
+	StateWinning
+	StateWon
+	StateGrappling
+)
+
+var PlayerStateNames = []string{
+	"Idle",
+	"Jumping",
+	"Falling",
+	"Slipping",
+	"Standing",
+	"Dying",
+	"Dead",
+	"Winning",
+	"Won",
+	"Grappling",
+}
+
+// Player is the Nanobot's body: where it is, what it's doing and how it moves
+type Player struct {
+	*resolv.Object
+	Input      Input
+	AnimState  PlayerAnimationTags
+	State      PlayerState
+	FrameTags  []FrameTag
+	Frame      int
+	Tick       int
+	JumpFrom   vector.Vector
+	GrappleTo  vector.Vector
+	WhatTiles  []string
+	Facing     Direction
+	Rotation   float64
+	SpeedX     float64
+	SpeedY     float64
+	Grip       float64     // How much longer they can hold on for, up to MaxGrip
+	Difficulty *Difficulty // How quickly and far they move
+	Events     []Event     // What happened to the player during the last tick
+}
+
+// NewPlayer creates the player at the given position, its state changes are
+// timed by the animations in the given frame tags
+func NewPlayer(position []int, frameTags []FrameTag) *Player {
+	object := resolv.NewObject(
+		float64(position[0]), float64(position[1]),
+		8, 8,
+	)
+	object.SetShape(resolv.NewRectangle(
+		0, 0, // origin
+		8, 8,
+	))
+
+	return &Player{
+		Object:     object,
+		FrameTags:  frameTags,
+		Grip:       MaxGrip,
+		Difficulty: &DifficultyNormal,
+	}
+}
+
+// Update steps the player by one tick
+func (p *Player) Update() {
+	p.Tick++
+	p.Events = p.Events[:0]
+
+	// Early return on death
+	if p.State == StateDying || p.State == StateDead {
+		p.updateDeath()
+		p.animate()
+		return
+	}
+	if p.State == StateWinning || p.State == StateWon {
+		return
+	}
+
+	anim, frame := p.AnimState, p.Frame
+	p.updateMovement()
+	p.collisionChecks()
+	p.updateGrip()
+	p.animate()
+	p.animationEvents(anim, frame)
+}
+
+func (p *Player) emit(e Event) {
+	p.Events = append(p.Events, e)
+}
+
+func (p *Player) updateDeath() {
+	p.Position.Y += speedDeathFall
+	p.Rotation += 0.02
+	p.Object.Update()
+}
+
+func (p *Player) updateMovement() {
+
+	// State-based continued movement
+	switch p.AnimState {
+
+	case PlayerNogrip:
+		p.SpeedX, p.SpeedY = 0, 0
+		return // let go, nothing to do but fall
+
+	case PlayerJumploop:
+		if (p.Input.ActionIsPressed(ActionPrimary) || !p.jumpedMin()) && !p.jumpedMax() {
+			p.AnimState = PlayerJumploop
+		} else {
+			p.AnimState = PlayerJumpendfloor
+		}
+		if p.Facing == DirectionLeft {
+			p.SpeedX, p.SpeedY = -p.Difficulty.JumpSpeed, 0
+		} else if p.Facing == DirectionRight {
+			p.SpeedX, p.SpeedY = +p.Difficulty.JumpSpeed, 0
+		} else if p.Facing == DirectionUp {
+			p.SpeedX, p.SpeedY = 0, -p.Difficulty.JumpSpeed
+		} else if p.Facing == DirectionDown {
+			p.SpeedX, p.SpeedY = 0, +p.Difficulty.JumpSpeed
+		}
+
+	case PlayerGrappleloop:
+		reel := p.GrappleTo.Sub(vector.Vector{p.Position.X, p.Position.Y})
+		if reel.Magnitude() <= speedGrapple {
+			p.AnimState = PlayerGrappleEnd
+			p.SpeedX, p.SpeedY = reel[0], reel[1]
+		} else if !p.Input.ActionIsPressed(ActionSecondary) {
+			// Letting go stops the reel where the player is, over a chasm
+			// they fall
+			p.AnimState = PlayerGrappleEnd
+			p.SpeedX, p.SpeedY = 0, 0
+		} else {
+			reel = reel.Unit().Scale(speedGrapple)
+			p.SpeedX, p.SpeedY = reel[0], reel[1]
+		}
+
+	case PlayerFallloop:
+		p.SpeedX, p.SpeedY = 0, speedFall
+	case PlayerSliploop:
+		p.SpeedX, p.SpeedY = 0, speedSliploop
+	case PlayerSlipend, PlayerSlipstart:
+		p.SpeedX, p.SpeedY = 0, speedSlip // XXX: why don't you slip without this?!
+	default:
+		p.SpeedX, p.SpeedY = 0, 0
+	}
+
+	// Walking in 1D input
+	if p.State == StateStanding {
+		if p.AnimState == PlayerSwitchtotopview {
+			return // no walking while switching
+		}
+
+		if p.Input.ActionIsPressed(ActionMoveLeft) {
+			p.SpeedX, p.SpeedY = -p.Difficulty.ClimbSpeed, 0
+			p.AnimState = PlayerWalkleft
+		} else if p.Input.ActionIsPressed(ActionMoveRight) {
+			p.SpeedX, p.SpeedY = +p.Difficulty.ClimbSpeed, 0
+			p.AnimState = PlayerWalkright
+		} else if p.Input.ActionIsPressed(ActionMoveUp) {
+			p.SpeedX, p.SpeedY = 0, -p.Difficulty.ClimbSpeed
+			p.AnimState = PlayerSwitchtotopview // intent to move up
+			p.Facing = DirectionUp
+		} else {
+			p.AnimState = PlayerStand
+		}
+		return
+	}
+
+	// Grapple input
+	if p.canGrapple() && p.Input.ActionIsJustPressed(ActionSecondary) {
+		if target, ok := p.grappleTarget(); ok {
+			p.State = StateGrappling
+			p.AnimState = PlayerGrapplestart
+			p.GrappleTo = target
+			p.SpeedX, p.SpeedY = 0, 0
+		}
+	}
+
+	// Jump input
+	if p.State != StateFalling && p.State != StateGrappling && (p.State != StateJumping || p.AnimState == PlayerJumpendfloor) && p.Input.ActionIsJustPressed(ActionPrimary) {
+		p.State = StateJumping
+		p.AnimState = PlayerJumpstart
+		p.JumpFrom = vector.Vector{p.Position.X, p.Position.Y}
+	}
+
+	// Climbing input
+	if p.State != StateJumping && p.State != StateFalling && p.State != StateSlipping && p.State != StateGrappling {
+		if p.Input.ActionIsPressed(ActionMoveLeft) {
+			p.SpeedX, p.SpeedY = -p.Difficulty.ClimbSpeed, 0
+			p.AnimState = PlayerClimb
+			p.Facing = DirectionLeft
+		} else if p.Input.ActionIsPressed(ActionMoveRight) {
+			p.SpeedX, p.SpeedY = +p.Difficulty.ClimbSpeed, 0
+			p.AnimState = PlayerClimb
+			p.Facing = DirectionRight
+		} else if p.Input.ActionIsPressed(ActionMoveUp) {
+			p.SpeedX, p.SpeedY = 0, -p.Difficulty.ClimbSpeed
+			p.AnimState = PlayerClimb
+			p.Facing = DirectionUp
+		} else if p.Input.ActionIsPressed(ActionMoveDown) {
+			p.SpeedX, p.SpeedY = 0, +p.Difficulty.ClimbSpeed
+			p.AnimState = PlayerClimb
+			p.Facing = DirectionDown
+		} else {
+			p.AnimState = PlayerIdle
+			p.SpeedX, p.SpeedY = 0, 0
+		}
+	}
+
+}
+
+func (p *Player) collisionChecks() {
+	if collision := p.Check(p.SpeedX, p.SpeedY); collision != nil {
+		for _, o := range collision.Objects {
+			if p.Shape.Intersection(0, 0, o.Shape) != nil {
+				p.WhatTiles = o.Tags()
+			}
+		}
+	}
+
+	dx := p.SpeedX
+	switch p.State {
+
+	case StateStanding:
+
+		// Don't walk into walls
+		if collision := p.Check(dx, 0, TagWall); collision != nil {
+			for _, o := range collision.Objects {
+				if intersection := p.Shape.Intersection(dx, 0, o.Shape); intersection != nil {
+					dx = 0
+				}
+			}
+		}
+
+		// // Fall down if there is no more wall beneath you // TODO: fixme!!!
+		// // XXX: this messes up the whole standing state!!!
+		// if collision := p.Check(p.H, dx, TagWall); collision == nil {
+		// 	p.AnimState = PlayerFallstart
+		// 	p.State = StateFalling
+		// 	p.Facing = DirectionUp
+		// }
+
+	case StateIdle: // Don't climb into a chasm
+		if collision := p.Check(dx, 0, TagChasm); collision != nil && !p.onPlatform(dx, 0) {
+			for _, o := range collision.Objects {
+				if intersection := p.Shape.Intersection(dx, 0, o.Shape); intersection != nil {
+					dx = 0
+				}
+			}
+		}
+		fallthrough
+
+	default:
+		if collision := p.Check(dx, 0, TagWall); collision != nil {
+			for _, o := range collision.Objects {
+				if intersection := p.Shape.Intersection(dx, 0, o.Shape); intersection != nil {
+					dx = intersection.MTV.X
+				}
+			}
+		}
+	}
+	p.Position.X += dx
+
+	dy := p.SpeedY
+	switch p.State {
+
+	case StateStanding:
+
+		// Successfully climb up if there's a climbable tile behind you
+		if dy < 0 { // attempting to climb up
+			if collision := p.Check(0, dy, TagClimbable); collision != nil {
+				canClimbUp := false
+				for _, o := range collision.ObjectsByTags(TagClimbable) {
+					if p.Overlaps(o) { // XXX: maybe this isn't even needed?!
+						canClimbUp = true
+					}
+				}
+				if !canClimbUp {
+					dy = 0 // no climbing for you today
+					p.AnimState = PlayerStand
+				}
+			}
+		}
+
+	default:
+		if collision := p.Check(0, dy, TagWall, TagClimbable, TagChasm); collision != nil {
+			for _, o := range collision.Objects {
+				if intersection := p.Shape.Intersection(0, dy, o.Shape); intersection != nil {
+
+					switch o.Tags()[0] {
+
+					case TagWall:
+						dy = 0
+						if p.State == StateFalling {
+							p.AnimState = PlayerFallendwall
+							log.Println("Avoid wall clipping after fall:", intersection.MTV.X, intersection.MTV.Y)
+							dy -= intersection.MTV.Y
+						}
+						if p.State == StateSlipping {
+							p.AnimState = PlayerSlipend
+						}
+						if p.State == StateJumping && p.AnimState != PlayerJumpendwall {
+							p.AnimState = PlayerJumpendwall
+							p.emit(EventJumpEndWall)
+							dy -= intersection.MTV.Y
+							if intersection.MTV.Y != 0 {
+								log.Println("MTV Y:", intersection.MTV.Y)
+							}
+						}
+					case TagChasm:
+						if p.State == StateIdle && !p.onPlatform(0, dy) { // Don't climb into chasm
+							dy = 0
+						}
+					case TagClimbable:
+						// only recover onto tiles below you, that means the MTV to
+						// get out of them will be negative, i.e. upwards
+						// log.Println("MTV WOOP:", intersection.MTV.Y)
+						if intersection.MTV.Y < 0 {
+							// log.Println("AAAAAAAAAAAA")
+							if p.AnimState == PlayerFallloop && p.Grip > 0 { // without grip, fall until standing
+								p.AnimState = PlayerFallendfloor
+							}
+							if p.AnimState == PlayerSliploop {
+								p.AnimState = PlayerSlipend
+							}
+						}
+					}
+				}
+			}
+		}
+		p.Position.Y += dy
+
+	}
+
+	// Start falling if you're stepping on a chasm
+	if p.AnimState != PlayerJumploop && p.AnimState != PlayerGrapplestart && p.AnimState != PlayerGrappleloop &&
+		p.State != StateFalling && p.State != StateSlipping && !p.onPlatform(0, 0) {
+		if collision := p.Check(dx, dy, TagChasm, TagSlippery); collision != nil {
+			for _, o := range collision.Objects {
+				if p.Shape.Intersection(dx, dy, o.Shape) != nil || p.insideOf(o) ||
+					o.HasTags(TagPlatform) && centreInside(o, p.Position.X+dx, p.Position.Y+dy, p) {
+					switch o.Tags()[0] {
+					case TagChasm:
+						p.AnimState = PlayerFallstart
+						p.State = StateFalling
+						p.Facing = DirectionUp
+					case TagSlippery:
+						p.AnimState = PlayerSlipstart
+						p.State = StateSlipping
+						p.Facing = DirectionUp
+					}
+				}
+			}
+		}
+	}
+
+	p.Object.Update()
+}
+
+func (p *Player) animate() {
+	if p.Frame == p.FrameTags[p.AnimState].To {
+		p.animationBasedStateChanges()
+	}
+	p.Frame = Animate(p.Frame, p.Tick, p.FrameTags[p.AnimState])
+}
+
+// animationEvents emits the events for changes of animation since the given
+// animation and frame of the last tick
+func (p *Player) animationEvents(anim PlayerAnimationTags, frame int) {
+	if p.AnimState == PlayerClimb && p.Frame == p.FrameTags[PlayerClimb].From && (anim != PlayerClimb || frame != p.Frame) {
+		p.emit(EventClimbStep)
+	}
+	if p.AnimState == anim {
+		return
+	}
+	switch p.AnimState {
+	case PlayerJumpendfloor, PlayerJumpendmantle:
+		p.emit(EventJumpEndFloor)
+	case PlayerSlipstart:
+		p.emit(EventSlipStart)
+	case PlayerFallendwall, PlayerFallendfloor:
+		p.emit(EventFallEnd)
+	case PlayerGrapplestart:
+		p.emit(EventGrapple)
+	case PlayerNogrip:
+		p.emit(EventNoGrip)
+	}
+}
+
+// Animation-trigged state changes
+func (p *Player) animationBasedStateChanges() {
+	switch p.AnimState {
+
+	case PlayerJumpstart:
+		p.AnimState = PlayerJumploop
+
+	case PlayerJumploop:
+		p.AnimState = PlayerJumploop
+
+	case PlayerJumpendwall, PlayerJumpendfloor, PlayerJumpendmantle:
+		p.State = StateIdle
+
+	case PlayerFallstart:
+		p.AnimState = PlayerFallloop
+
+	case PlayerFallendwall:
+		p.AnimState = PlayerStand
+		p.State = StateStanding
+
+	case PlayerFallendfloor:
+		p.AnimState = PlayerIdle
+		p.State = StateIdle
+
+	case PlayerSwitchtotopview:
+		p.AnimState = PlayerIdle
+		p.State = StateIdle
+
+	case PlayerNogrip:
+		p.AnimState = PlayerFallstart
+		p.State = StateFalling
+		p.Facing = DirectionUp
+
+	case PlayerSlipstart:
+		p.AnimState = PlayerSliploop
+
+	case PlayerSlipend:
+		p.AnimState = PlayerIdle
+		p.State = StateIdle
+
+	case PlayerGrapplestart:
+		p.AnimState = PlayerGrappleloop
+
+	case PlayerGrappleloop:
+		p.AnimState = PlayerGrappleloop
+
+	case PlayerGrappleEnd:
+		p.AnimState = PlayerIdle
+		p.State = StateIdle
+
+	}
+}
+
+// canGrapple reports whether the hook can be fired from the current animation
+func (p *Player) canGrapple() bool {
+	switch p.AnimState {
+	case PlayerIdle, PlayerClimb, PlayerJumploop, PlayerFallloop, PlayerSliploop:
+		return true
+	}
+	return false
+}
+
+// grappleTarget casts a line from the player in the direction they're facing
+// and returns where to reel in to if it hits a climbable tile within range
+// before hitting a wall. The climbable tiles right next to the one the player
+// is on can be climbed to, so the hook only catches across a gap
+func (p *Player) grappleTarget() (vector.Vector, bool) {
+	if p.Space == nil {
+		return nil, false
+	}
+
+	dx, dy := 0, 0
+	switch p.Facing {
+	case DirectionUp:
+		dy = -1
+	case DirectionRight:
+		dx = 1
+	case DirectionDown:
+		dy = 1
+	case DirectionLeft:
+		dx = -1
+	}
+
+	cx, cy := p.Space.WorldToSpace(p.Position.X, p.Position.Y)
+	gap := true
+	if cell := p.Space.Cell(cx, cy); cell != nil {
+		gap = !cell.ContainsTags(TagClimbable)
+	}
+	for i := 1; i <= GrappleRange/GridSize; i++ {
+		cell := p.Space.Cell(cx+dx*i, cy+dy*i)
+		if cell == nil || cell.ContainsTags(TagWall) {
+			return nil, false
+		}
+		if !cell.ContainsTags(TagClimbable) {
+			gap = true
+		} else if gap {
+			x, y := p.Space.SpaceToWorld(cell.X, cell.Y)
+			return vector.Vector{x + GridSize/2, y + GridSize/2}, true
+		}
+	}
+	return nil, false
+}
+
+// onPlatform reports whether the player moved by dx, dy would be held up by a
+// moving platform or crumbling tile, which keep it from falling into chasms
+func (p *Player) onPlatform(dx, dy float64) bool {
+	if collision := p.Check(dx, dy, TagPlatform); collision != nil {
+		for _, o := range collision.Objects {
+			if o.HasTags(TagClimbable) && centreInside(o, p.Position.X+dx, p.Position.Y+dy, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Player) insideOf(o *resolv.Object) bool {
+	if o.Shape == nil {
+		return false
+	}
+
+	verts := p.Shape.(*resolv.ConvexPolygon).Transformed()
+	for _, v := range verts {
+		if !o.Shape.(*resolv.ConvexPolygon).PointInside(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Player) jumpedMax() bool {
+	return math.Abs(p.jumpDistance().Magnitude()) >= p.Difficulty.MaxJumpDist
+}
+
+func (p *Player) jumpedMin() bool {
+	return math.Abs(p.jumpDistance().Magnitude()) >= p.Difficulty.MinJumpDist
+}
+
+func (p *Player) jumpDistance() vector.Vector {
+	return vector.Vector{p.Position.X, p.Position.Y}.Sub(p.JumpFrom)
+}
@@ -0,0 +1,68 @@
+package sim
+
+// DO NOT EDIT
+// Generated by: ../tools/gen_sprite_tags.sh
+
+type PlayerAnimationTags uint8
+
+const (
+	PlayerIdle PlayerAnimationTags = iota
+	PlayerClimb
+	PlayerLeanstart
+	PlayerLeanloop
+	PlayerLeanend
+	PlayerPushwallstart
+	PlayerPushwallloop
+	PlayerPushwallend
+	PlayerNogrip
+	PlayerJumpstart
+	PlayerJumploop
+	PlayerJumpendfloor
+	PlayerJumpendmantle
+	PlayerJumpendwall
+	PlayerSlipstart
+	PlayerSliploop
+	PlayerSlipend
+	PlayerFallstart
+	PlayerFallloop
+	PlayerFallendfloor
+	PlayerFallendwall
+	PlayerStand
+	PlayerWalkright
+	PlayerWalkleft
+	PlayerSwitchtotopview
+	PlayerGrapplestart
+	PlayerGrappleloop
+	PlayerGrappleEnd
+)
+
+var PlayerAnimationNames = []string{
+	"Idle",
+	"Climb",
+	"Lean start",
+	"Lean loop",
+	"Lean end",
+	"Push wall start",
+	"Push wall loop",
+	"Push wall end",
+	"No grip",
+	"Jump start",
+	"Jump loop",
+	"Jump end floor",
+	"Jump end mantle",
+	"Jump end wall",
+	"Slip start",
+	"Slip loop",
+	"Slip end",
+	"Fall start",
+	"Fall loop",
+	"Fall end floor",
+	"Fall end wall",
+	"Stand",
+	"Walk right",
+	"Walk left",
+	"Switch to topview",
+	"Grapple start",
+	"Grapple loop",
+	"Grapple End",
+}
@@ -0,0 +1,289 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package sim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"io"
+
+	"github.com/solarlune/ldtkgo"
+)
+
+// replayMagic starts every replay file, followed by the format version
+const replayMagic = "PSRP"
+const replayVersion = 1
+
+// The longest round a replay can hold, a day of ticks
+const maxReplayTicks = 24 * 60 * 60 * TPS
+
+var errCorruptReplay = errors.New("corrupt replay file")
+
+// Ticker is an Input that needs to be told when a tick has passed
+type Ticker interface {
+	Tick()
+}
+
+// InputState is which actions were pressed during a tick, one bit per action
+// in the low byte and whether it was just pressed in the high byte
+type InputState uint16
+
+// CaptureInput reads the state of every action from an input
+func CaptureInput(in Input) InputState {
+	var state InputState
+	for a := ActionMoveUp; a <= ActionSecondary; a++ {
+		if in.ActionIsPressed(a) {
+			state |= 1 << a
+		}
+		if in.ActionIsJustPressed(a) {
+			state |= 1 << (a + 8)
+		}
+	}
+	return state
+}
+
+// Pressed tells whether the action was held down during the tick
+func (s InputState) Pressed(action Action) bool {
+	return s&(1<<action) != 0
+}
+
+// JustPressed tells whether the action started being held down on the tick
+func (s InputState) JustPressed(action Action) bool {
+	return s&(1<<(action+8)) != 0
+}
+
+// Replay is the input of every tick of a round, enough to play it back exactly
+// on the same level
+type Replay struct {
+	Level      string       // Identifier of the level played
+	MapHash    uint64       // Hash of the level, see HashLevel
+	Ticks      []InputState // Input of each tick in order
+	Difficulty Difficulty   // Difficulty the round was played on
+	Respawns   []int        // Ticks at which the player respawned at a checkpoint
+}
+
+// NewReplay starts an empty replay of a level played on a difficulty
+func NewReplay(level *ldtkgo.Level, difficulty *Difficulty) *Replay {
+	return &Replay{
+		Level:      level.Identifier,
+		MapHash:    HashLevel(level),
+		Difficulty: *difficulty,
+	}
+}
+
+// HashLevel sums up everything in a level that affects the simulation, so a
+// replay can tell if it's being played on a different version of the map
+func HashLevel(level *ldtkgo.Level) uint64 {
+	h := fnv.New64a()
+	put := func(values ...int) {
+		for _, v := range values {
+			binary.Write(h, binary.LittleEndian, int64(v))
+		}
+	}
+
+	io.WriteString(h, level.Identifier)
+	put(level.Width, level.Height)
+	for _, layer := range level.Layers {
+		io.WriteString(h, layer.Identifier)
+		for _, tile := range layer.AllTiles() {
+			put(tile.Position[0], tile.Position[1], tile.ID)
+		}
+		for _, entity := range layer.Entities {
+			io.WriteString(h, entity.Identifier)
+			put(entity.Position[0], entity.Position[1], entity.Width, entity.Height)
+		}
+	}
+	return h.Sum64()
+}
+
+// MarshalBinary encodes the replay compactly, runs of ticks with the same
+// input are stored once with how many times they repeat
+func (r *Replay) MarshalBinary() ([]byte, error) {
+	b := []byte(replayMagic)
+	b = append(b, replayVersion)
+	b = binary.LittleEndian.AppendUint64(b, r.MapHash)
+	b = binary.AppendUvarint(b, uint64(len(r.Level)))
+	b = append(b, r.Level...)
+
+	// The whole difficulty is kept, not just its name, so that replays of
+	// custom difficulties play back the same
+	difficulty, err := json.Marshal(r.Difficulty)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.AppendUvarint(b, uint64(len(difficulty)))
+	b = append(b, difficulty...)
+
+	b = binary.AppendUvarint(b, uint64(len(r.Respawns)))
+	for _, tick := range r.Respawns {
+		b = binary.AppendUvarint(b, uint64(tick))
+	}
+
+	b = binary.AppendUvarint(b, uint64(len(r.Ticks)))
+	for i := 0; i < len(r.Ticks); {
+		run := 1
+		for i+run < len(r.Ticks) && r.Ticks[i+run] == r.Ticks[i] {
+			run++
+		}
+		b = binary.AppendUvarint(b, uint64(run))
+		b = binary.AppendUvarint(b, uint64(r.Ticks[i]))
+		i += run
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a replay encoded by MarshalBinary, replays are shared
+// between players so anything that doesn't add up is a corrupt replay file
+func (r *Replay) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewReader(data)
+	magic := make([]byte, len(replayMagic)+1)
+	if _, err := io.ReadFull(buf, magic); err != nil || string(magic[:len(replayMagic)]) != replayMagic {
+		return errors.New("not a replay file")
+	}
+	if magic[len(replayMagic)] != replayVersion {
+		return errors.New("unsupported replay version")
+	}
+
+	var err error
+	uvarint := func() uint64 {
+		if err != nil {
+			return 0
+		}
+		var v uint64
+		v, err = binary.ReadUvarint(buf)
+		return v
+	}
+	// field reads a length and then that many bytes, which have to be there
+	field := func() []byte {
+		n := uvarint()
+		if err != nil {
+			return nil
+		}
+		if n > uint64(buf.Len()) {
+			err = errCorruptReplay
+			return nil
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(buf, b)
+		return b
+	}
+
+	if err = binary.Read(buf, binary.LittleEndian, &r.MapHash); err != nil {
+		return err
+	}
+	r.Level = string(field())
+	difficulty := field()
+	if err == nil {
+		r.Difficulty = DifficultyNormal
+		if json.Unmarshal(difficulty, &r.Difficulty) != nil {
+			return errCorruptReplay
+		}
+	}
+
+	// Every respawn takes at least a byte
+	r.Respawns = nil
+	respawns := uvarint()
+	if err == nil && respawns > uint64(buf.Len()) {
+		return errCorruptReplay
+	}
+	for i := respawns; i > 0 && err == nil; i-- {
+		r.Respawns = append(r.Respawns, int(uvarint()))
+	}
+
+	total := uvarint()
+	if err == nil && total > maxReplayTicks {
+		return errCorruptReplay
+	}
+	r.Ticks = nil
+	for uint64(len(r.Ticks)) < total && err == nil {
+		run, state := uvarint(), InputState(uvarint())
+		if err == nil && (run == 0 || uint64(len(r.Ticks))+run > total) {
+			return errCorruptReplay
+		}
+		for ; run > 0; run-- {
+			r.Ticks = append(r.Ticks, state)
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Recorder passes input through to the simulation while writing down the
+// state of every tick into a replay
+type Recorder struct {
+	Input  Input
+	Replay *Replay
+}
+
+func (r *Recorder) ActionIsPressed(action Action) bool {
+	return r.Input.ActionIsPressed(action)
+}
+
+func (r *Recorder) ActionIsJustPressed(action Action) bool {
+	return r.Input.ActionIsJustPressed(action)
+}
+
+// Tick writes down the input of the tick that just passed
+func (r *Recorder) Tick() {
+	r.Replay.Ticks = append(r.Replay.Ticks, CaptureInput(r.Input))
+}
+
+// Respawned writes down that the player continued from a checkpoint before
+// the next tick
+func (r *Recorder) Respawned() {
+	r.Replay.Respawns = append(r.Replay.Respawns, len(r.Replay.Ticks))
+}
+
+// ReplayInput feeds the input of a replay back to the simulation one tick at
+// a time, after the last tick nothing is pressed
+type ReplayInput struct {
+	Replay *Replay
+	Index  int // Tick being played
+}
+
+// NewReplayInput plays a replay from its first tick
+func NewReplayInput(replay *Replay) *ReplayInput {
+	return &ReplayInput{Replay: replay}
+}
+
+func (r *ReplayInput) current() InputState {
+	if r.Index < len(r.Replay.Ticks) {
+		return r.Replay.Ticks[r.Index]
+	}
+	return 0
+}
+
+func (r *ReplayInput) ActionIsPressed(action Action) bool {
+	return r.current().Pressed(action)
+}
+
+func (r *ReplayInput) ActionIsJustPressed(action Action) bool {
+	return r.current().JustPressed(action)
+}
+
+// Tick moves on to the input of the next tick
+func (r *ReplayInput) Tick() {
+	r.Index++
+}
+
+// Done tells whether every tick of the replay has been played
+func (r *ReplayInput) Done() bool {
+	return r.Index >= len(r.Replay.Ticks)
+}
+
+// RespawnDue tells whether the player respawned at a checkpoint at this point
+// of the recording
+func (r *ReplayInput) RespawnDue() bool {
+	for _, tick := range r.Replay.Respawns {
+		if tick == r.Index {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package sim
+
+import (
+	"encoding/binary"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// recordRound plays a level with the given input, recording it until the
+// finish is reached
+func recordRound(t *testing.T, input *script, rows ...string) (*World, *Replay) {
+	t.Helper()
+	w := newTestWorld(t, nil, rows...)
+	recorder := &Recorder{Input: input, Replay: NewReplay(w.Level, w.Difficulty)}
+	w.Player.Input = recorder
+	for range 600 {
+		w.Step()
+		input.Tick()
+		if slices.Contains(w.Events, EventFinish) {
+			return w, recorder.Replay
+		}
+	}
+	t.Fatal("the recorded round didn't reach the finish")
+	return nil, nil
+}
+
+var replayLevel = []string{
+	"#F#",
+	"#.#",
+	"#.#",
+	"#.#",
+	"#~#",
+	"#~#",
+	"#.#",
+	"#.#",
+	"#S#",
+}
+
+// replayScript climbs up the replay level, jumping over its chasm
+func replayScript() *script {
+	return &script{presses: []press{
+		{ActionMoveUp, 0, 30},
+		{ActionPrimary, 30, 90},
+		{ActionMoveUp, 90, 600},
+	}}
+}
+
+func TestReplay(t *testing.T) {
+	recorded, replay := recordRound(t, replayScript(), replayLevel...)
+
+	data, err := replay.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Replay
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Level != replay.Level || loaded.MapHash != replay.MapHash || loaded.Difficulty.Name != replay.Difficulty.Name {
+		t.Errorf("loaded replay of %s %x on %s, want %s %x on %s",
+			loaded.Level, loaded.MapHash, loaded.Difficulty.Name, replay.Level, replay.MapHash, replay.Difficulty.Name)
+	}
+	if !slices.Equal(loaded.Ticks, replay.Ticks) {
+		t.Fatalf("loaded %d ticks of input, want the %d recorded", len(loaded.Ticks), len(replay.Ticks))
+	}
+
+	played := newTestWorld(t, NewReplayInput(&loaded), replayLevel...)
+	for range loaded.Ticks {
+		played.Step()
+	}
+	if played.Player.State != StateWinning {
+		t.Errorf("player is %s at the end of the replay, want Winning", PlayerStateNames[played.Player.State])
+	}
+	if played.Player.Position != recorded.Player.Position {
+		t.Errorf("replay ended at %v, want %v where the recording did", played.Player.Position, recorded.Player.Position)
+	}
+	if !slices.Equal(played.Trace, recorded.Trace) || played.Elapsed() != recorded.Elapsed() {
+		t.Errorf("replay took %v along a different path, want %v", played.Elapsed(), recorded.Elapsed())
+	}
+}
+
+func TestReplayCorrupt(t *testing.T) {
+	_, replay := recordRound(t, replayScript(), replayLevel...)
+	data, err := replay.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Everything up to the level name, which starts with its length
+	header := append([]byte(replayMagic), replayVersion)
+	header = binary.LittleEndian.AppendUint64(header, replay.MapHash)
+	after := func(prefix []byte, values ...uint64) []byte {
+		b := slices.Clone(prefix)
+		for _, v := range values {
+			b = binary.AppendUvarint(b, v)
+		}
+		return b
+	}
+	difficulty := append(after(header, 0, 2), "{}"...)
+
+	tests := map[string][]byte{
+		"huge level name":           after(header, 1<<62),
+		"level name too long":       append(after(header, 100), "Level_0"...),
+		"huge difficulty":           after(header, 0, 1<<62),
+		"huge respawn count":        after(difficulty, 1<<62),
+		"huge tick count":           after(difficulty, 0, 1<<62),
+		"run longer than the ticks": after(difficulty, 0, 2, 3, 0),
+	}
+	for i := range data {
+		tests["truncated to "+strconv.Itoa(i)+" bytes"] = data[:i]
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var loaded Replay
+			if err := loaded.UnmarshalBinary(data); err == nil {
+				t.Errorf("loaded %d ticks of %s without an error", len(loaded.Ticks), loaded.Level)
+			}
+		})
+	}
+}
@@ -0,0 +1,400 @@
+package sim
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Move is how the player gets from one tile to the next on a route
+type Move uint8
+
+const (
+	MoveStart   Move = iota // Where the route starts
+	MoveClimb               // Climbed onto a neighbouring tile
+	MoveWalk                // Walked along the top of a wall
+	MoveJump                // Jumped over one or more tiles
+	MoveFall                // Fell one tile down a chasm
+	MoveSlip                // Slipped one tile down slippery terrain
+	MoveGrapple             // Reeled in to a climbable tile with the hook
+)
+
+var MoveNames = []string{
+	"Start",
+	"Climb",
+	"Walk",
+	"Jump",
+	"Fall",
+	"Slip",
+	"Grapple",
+}
+
+// RouteStep is a tile on the way to the finish and how it was reached
+type RouteStep struct {
+	X, Y  int // Tile in the collision space
+	Move  Move
+	Ticks int     // Ticks from the start of the route until this tile is reached
+	Grip  float64 // Grip left on reaching the tile, up to MaxGrip
+}
+
+// Route is the quickest way from the start to the finish the solver found
+type Route struct {
+	Steps []RouteStep
+	Ticks int // Ticks the whole route takes
+}
+
+// Kind of tile as far as moving through it is concerned, when a tile has
+// several the later one wins
+type tileKind uint8
+
+const (
+	kindEmpty tileKind = iota
+	kindClimbable
+	kindSlippery
+	kindChasm
+	kindWall
+)
+
+// What the player is doing on a tile, which decides what it can do next
+type solverMode uint8
+
+const (
+	modeIdle     solverMode = iota // Climbing, can move, jump and grapple any way
+	modeStanding                   // On top of a wall, can walk along it or climb up
+	modeSlipping                   // Sliding down, can still jump or grapple upwards
+	modeFalling                    // Falling down, can still grapple upwards
+)
+
+type solverNode struct {
+	X, Y int
+	Mode solverMode
+}
+
+// Grip in the solver is counted in ticks of climbing ordinary tiles
+const (
+	gripTicks        = int(MaxGrip / gripDrain)
+	gripHardTicks    = int(gripDrainHard / gripDrain)
+	gripRecoverTicks = int(gripRecover / gripDrain)
+	gripBucket       = 60 // A second of climbing, see solverState
+)
+
+// solverState is a node reached with some grip left. Grip is bucketed so a
+// node is only searched again with a second more or less of it, the quickest
+// way into a bucket keeps its exact grip so a route never holds on longer
+// than the player can
+type solverState struct {
+	solverNode
+	Grip int // Grip left in buckets of gripBucket
+}
+
+// Ticks it takes to cross one tile with each kind of movement that doesn't
+// depend on the difficulty, the animations at the start and end of a move are
+// added by the solver
+var (
+	ticksFall    = tileTicks(speedFall)
+	ticksSlip    = tileTicks(speedSliploop)
+	ticksGrapple = tileTicks(speedGrapple)
+)
+
+func tileTicks(speed float64) int {
+	return int(math.Ceil(GridSize / speed))
+}
+
+var directions = [][2]int{
+	DirectionUp:    {0, -1},
+	DirectionRight: {1, 0},
+	DirectionDown:  {0, 1},
+	DirectionLeft:  {-1, 0},
+}
+
+// Solver searches a level tile by tile for the quickest way to the finish,
+// following the same rules for climbing, jumping, falling, slipping and
+// grappling as the player does in collisionChecks. It keeps track of grip
+// too, climbing uses it up and walking on top of a wall brings it back, but
+// it doesn't stand still to rest
+type Solver struct {
+	Width, Height int
+	kinds         []tileKind
+	finish        []bool
+	hard          []bool // Hard grip tiles
+
+	// Ticks spent in the animations at the start and end of each move
+	jumpExtra, grappleExtra, fallExtra, slipExtra int
+
+	// How the difficulty moves the player, in ticks per tile and tiles
+	ticksClimb, ticksJump      int
+	minJumpTiles, maxJumpTiles int
+}
+
+// NewSolver reads what kind of tile is where from the world's collision space,
+// moving the player as fast and far as the world's difficulty allows
+func NewSolver(w *World) *Solver {
+	tags := w.Player.FrameTags
+	d := w.Player.Difficulty
+	s := &Solver{
+		Width:        w.Space.Width(),
+		Height:       w.Space.Height(),
+		jumpExtra:    animationTicks(tags, PlayerJumpstart) + animationTicks(tags, PlayerJumpendfloor),
+		grappleExtra: animationTicks(tags, PlayerGrapplestart) + animationTicks(tags, PlayerGrappleEnd),
+		fallExtra:    animationTicks(tags, PlayerFallstart) + animationTicks(tags, PlayerFallendfloor),
+		slipExtra:    animationTicks(tags, PlayerSlipstart) + animationTicks(tags, PlayerSlipend),
+		ticksClimb:   tileTicks(d.ClimbSpeed),
+		ticksJump:    tileTicks(d.JumpSpeed),
+		minJumpTiles: int(math.Round(d.MinJumpDist / GridSize)),
+		maxJumpTiles: int(math.Round(d.MaxJumpDist / GridSize)),
+	}
+	s.kinds = make([]tileKind, s.Width*s.Height)
+	s.finish = make([]bool, s.Width*s.Height)
+	s.hard = make([]bool, s.Width*s.Height)
+	for y := 0; y < s.Height; y++ {
+		for x := 0; x < s.Width; x++ {
+			cell := w.Space.Cell(x, y)
+			i := y*s.Width + x
+			for _, o := range cell.Objects {
+				// Platforms move and crumble so the route never relies on them
+				if o.HasTags(TagPlatform) {
+					continue
+				}
+				s.hard[i] = s.hard[i] || o.HasTags(TagHardGrip)
+				switch {
+				case o.HasTags(TagWall):
+					s.kinds[i] = max(s.kinds[i], kindWall)
+				case o.HasTags(TagChasm):
+					s.kinds[i] = max(s.kinds[i], kindChasm)
+				case o.HasTags(TagSlippery):
+					s.kinds[i] = max(s.kinds[i], kindSlippery)
+				case o.HasTags(TagClimbable):
+					s.kinds[i] = max(s.kinds[i], kindClimbable)
+				}
+			}
+			s.finish[i] = cell.ContainsTags(TagFinish)
+		}
+	}
+	return s
+}
+
+// animationTicks is how long an animation plays for, nothing if the frame
+// tags weren't loaded
+func animationTicks(tags []FrameTag, anim PlayerAnimationTags) int {
+	if int(anim) >= len(tags) {
+		return 0
+	}
+	return (tags[anim].To - tags[anim].From + 1) * AnimationSkipTicks
+}
+
+// Solve finds the quickest route from the start of the level to the finish,
+// false if the finish can't be reached
+func (w *World) Solve() (*Route, bool) {
+	x, y := w.Space.WorldToSpace(
+		float64(w.StartPos[0])+w.Player.Size.X/2,
+		float64(w.StartPos[1])+w.Player.Size.Y/2,
+	)
+	return NewSolver(w).Solve(x, y)
+}
+
+// Solve finds the quickest route from the given tile to the finish, false if
+// the finish can't be reached
+func (s *Solver) Solve(x, y int) (*Route, bool) {
+	type visit struct {
+		from  solverState
+		move  Move
+		ticks int
+		grip  int // Exact grip left
+	}
+	start := solverState{solverNode{x, y, modeIdle}, gripTicks / gripBucket}
+	visited := map[solverState]visit{start: {start, MoveStart, 0, gripTicks}}
+	done := map[solverState]bool{}
+	queue := &solverQueue{{start, 0}}
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(solverItem)
+		state := item.state
+		if done[state] {
+			continue
+		}
+		done[state] = true
+
+		if s.finish[state.Y*s.Width+state.X] {
+			route := &Route{Ticks: item.ticks}
+			for {
+				v := visited[state]
+				grip := float64(v.grip) / float64(gripTicks) * MaxGrip
+				route.Steps = append(route.Steps, RouteStep{state.X, state.Y, v.move, v.ticks, grip})
+				if v.move == MoveStart {
+					break
+				}
+				state = v.from
+			}
+			for i, j := 0, len(route.Steps)-1; i < j; i, j = i+1, j-1 {
+				route.Steps[i], route.Steps[j] = route.Steps[j], route.Steps[i]
+			}
+			return route, true
+		}
+
+		grip := visited[state].grip
+		s.moves(state.solverNode, func(next solverNode, move Move, ticks int) {
+			left, ok := s.gripAfter(state.solverNode, next, move, ticks, grip)
+			if !ok {
+				return
+			}
+			key := solverState{next, left / gripBucket}
+			ticks += item.ticks
+			if v, ok := visited[key]; ok && v.ticks <= ticks {
+				return
+			}
+			visited[key] = visit{state, move, ticks, left}
+			heap.Push(queue, solverItem{key, ticks})
+		})
+	}
+	return nil, false
+}
+
+// gripAfter is how much grip is left after a move, false if it runs out on the
+// way. Climbing uses it up, faster on hard grip tiles, walking on top of a wall
+// brings it back and nothing else holds on
+func (s *Solver) gripAfter(from, to solverNode, move Move, ticks, grip int) (int, bool) {
+	switch move {
+	case MoveClimb:
+		if s.hard[from.Y*s.Width+from.X] || s.hard[to.Y*s.Width+to.X] {
+			ticks *= gripHardTicks
+		}
+		grip -= ticks
+		return grip, grip > 0
+	case MoveWalk:
+		return min(grip+ticks*gripRecoverTicks, gripTicks), true
+	}
+	return grip, true
+}
+
+// kind of the tile at x, y where everything outside the level is a wall
+func (s *Solver) kind(x, y int) tileKind {
+	if x < 0 || y < 0 || x >= s.Width || y >= s.Height {
+		return kindWall
+	}
+	return s.kinds[y*s.Width+x]
+}
+
+// moves calls add for every tile the player can get to from the node
+func (s *Solver) moves(n solverNode, add func(next solverNode, move Move, ticks int)) {
+	switch n.Mode {
+
+	case modeIdle:
+		for _, d := range directions {
+			x, y := n.X+d[0], n.Y+d[1]
+			switch s.kind(x, y) {
+			case kindEmpty, kindClimbable:
+				add(solverNode{x, y, modeIdle}, MoveClimb, s.ticksClimb)
+			case kindSlippery:
+				add(solverNode{x, y, modeSlipping}, MoveClimb, s.ticksClimb)
+			}
+			s.jump(n, d, add)
+			s.grapple(n, d, add)
+		}
+
+	case modeStanding:
+		for _, d := range []Direction{DirectionLeft, DirectionRight} {
+			x, y := n.X+directions[d][0], n.Y
+			if s.kind(x, y) != kindWall && s.kind(x, y+1) == kindWall {
+				add(solverNode{x, y, modeStanding}, MoveWalk, s.ticksClimb)
+			}
+		}
+		if s.kind(n.X, n.Y) == kindClimbable {
+			add(solverNode{n.X, n.Y, modeIdle}, MoveClimb, 0)
+		}
+		if k := s.kind(n.X, n.Y-1); k == kindClimbable || k == kindEmpty {
+			add(solverNode{n.X, n.Y - 1, modeIdle}, MoveClimb, s.ticksClimb)
+		}
+
+	case modeSlipping:
+		switch s.kind(n.X, n.Y+1) {
+		case kindWall:
+			add(solverNode{n.X, n.Y, modeIdle}, MoveSlip, s.slipExtra)
+		case kindClimbable:
+			add(solverNode{n.X, n.Y + 1, modeIdle}, MoveSlip, ticksSlip+s.slipExtra)
+		default:
+			add(solverNode{n.X, n.Y + 1, modeSlipping}, MoveSlip, ticksSlip)
+		}
+		// Slipping always faces up
+		s.jump(n, directions[DirectionUp], add)
+		s.grapple(n, directions[DirectionUp], add)
+
+	case modeFalling:
+		switch s.kind(n.X, n.Y+1) {
+		case kindWall:
+			add(solverNode{n.X, n.Y, modeStanding}, MoveFall, s.fallExtra)
+		case kindClimbable:
+			add(solverNode{n.X, n.Y + 1, modeIdle}, MoveFall, ticksFall+s.fallExtra)
+		default:
+			add(solverNode{n.X, n.Y + 1, modeFalling}, MoveFall, ticksFall)
+		}
+		s.grapple(n, directions[DirectionUp], add)
+	}
+}
+
+// jump adds every tile a jump in one direction can land on, jumps go over
+// chasms but stop short of walls
+func (s *Solver) jump(n solverNode, d [2]int, add func(next solverNode, move Move, ticks int)) {
+	for i := 1; i <= s.maxJumpTiles; i++ {
+		x, y := n.X+d[0]*i, n.Y+d[1]*i
+		if s.kind(x, y) == kindWall {
+			if i > 1 {
+				s.land(x-d[0], y-d[1], i-1, add)
+			}
+			return
+		}
+		if i >= s.minJumpTiles {
+			s.land(x, y, i, add)
+		}
+	}
+}
+
+// land adds the tile a jump ends on, depending on what's there the player
+// holds on, falls or slips
+func (s *Solver) land(x, y, tiles int, add func(next solverNode, move Move, ticks int)) {
+	mode := modeIdle
+	switch s.kind(x, y) {
+	case kindChasm:
+		mode = modeFalling
+	case kindSlippery:
+		mode = modeSlipping
+	}
+	add(solverNode{x, y, mode}, MoveJump, tiles*s.ticksJump+s.jumpExtra)
+}
+
+// grapple adds the first climbable tile within range in one direction after a
+// gap, unless there's a wall in the way, like grappleTarget does
+func (s *Solver) grapple(n solverNode, d [2]int, add func(next solverNode, move Move, ticks int)) {
+	gap := s.kind(n.X, n.Y) != kindClimbable
+	for i := 1; i <= GrappleRange/GridSize; i++ {
+		x, y := n.X+d[0]*i, n.Y+d[1]*i
+		switch s.kind(x, y) {
+		case kindWall:
+			return
+		case kindClimbable:
+			if gap {
+				add(solverNode{x, y, modeIdle}, MoveGrapple, i*ticksGrapple+s.grappleExtra)
+				return
+			}
+		default:
+			gap = true
+		}
+	}
+}
+
+type solverItem struct {
+	state solverState
+	ticks int
+}
+
+// solverQueue is a priority queue of the nodes to visit next, quickest first
+type solverQueue []solverItem
+
+func (q solverQueue) Len() int           { return len(q) }
+func (q solverQueue) Less(i, j int) bool { return q[i].ticks < q[j].ticks }
+func (q solverQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *solverQueue) Push(x any)        { *q = append(*q, x.(solverItem)) }
+func (q *solverQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,178 @@
+package sim
+
+import (
+	"os"
+	"testing"
+)
+
+// solveLevel finds the quickest route through a level built by testLevel
+func solveLevel(t *testing.T, rows ...string) (*World, *Route, bool) {
+	t.Helper()
+	w := newTestWorld(t, &script{}, rows...)
+	route, ok := w.Solve()
+	return w, route, ok
+}
+
+// moves counts how many times each move is made on a route
+func moves(route *Route) map[Move]int {
+	count := map[Move]int{}
+	for _, step := range route.Steps {
+		count[step.Move]++
+	}
+	return count
+}
+
+func TestSolveJumpOverChasm(t *testing.T) {
+	_, route, ok := solveLevel(t,
+		"#F#",
+		"#.#",
+		"#~#",
+		"#~#",
+		"#S#",
+	)
+	if !ok {
+		t.Fatal("no route over the chasm")
+	}
+	count := moves(route)
+	if count[MoveJump] != 1 || count[MoveFall] != 0 || count[MoveGrapple] != 0 {
+		t.Errorf("route makes moves %v, want one jump over the chasm", count)
+	}
+	if last := route.Steps[len(route.Steps)-1]; last.X != 1 || last.Y != 0 || last.Ticks != route.Ticks {
+		t.Errorf("route ends on tile %d, %d after %d of %d ticks, want the finish on 1, 0", last.X, last.Y, last.Ticks, route.Ticks)
+	}
+}
+
+func TestSolveGrapple(t *testing.T) {
+	// Too far to jump, but in reach of the hook
+	_, route, ok := solveLevel(t,
+		"#F#",
+		"#.#",
+		"#~#",
+		"#~#",
+		"#~#",
+		"#~#",
+		"#~#",
+		"#S#",
+	)
+	if !ok {
+		t.Fatal("no route across the chasm")
+	}
+	if count := moves(route); count[MoveGrapple] == 0 {
+		t.Errorf("route makes moves %v, want a grapple across the chasm", count)
+	}
+}
+
+func TestSolveWallBlocksGrapple(t *testing.T) {
+	w, _, ok := solveLevel(t,
+		"#F#",
+		"#.#",
+		"#~#",
+		"#~#",
+		"###",
+		"#~#",
+		"#~#",
+		"#S#",
+	)
+	if ok {
+		t.Error("found a route through the wall")
+	}
+
+	s := NewSolver(w)
+	s.grapple(solverNode{1, 7, modeIdle}, directions[DirectionUp], func(next solverNode, move Move, ticks int) {
+		t.Errorf("grappled to tile %d, %d through the wall", next.X, next.Y)
+	})
+}
+
+func TestSolveGrappleNeedsGap(t *testing.T) {
+	// The hook doesn't catch on the climbable tile right above
+	w, _, _ := solveLevel(t,
+		"#F#",
+		"#.#",
+		"#S#",
+	)
+	s := NewSolver(w)
+	s.grapple(solverNode{1, 2, modeIdle}, directions[DirectionUp], func(next solverNode, move Move, ticks int) {
+		t.Errorf("grappled to tile %d, %d without a gap", next.X, next.Y)
+	})
+}
+
+func TestSolveGrip(t *testing.T) {
+	// A column too long to climb on hard grip tiles, without jumps to get
+	// up it for free
+	column := func(tile string, height int) []string {
+		rows := []string{"#F#"}
+		for range height {
+			rows = append(rows, "#"+tile+"#")
+		}
+		return append(rows, "#S#")
+	}
+	for _, tc := range []struct {
+		name string
+		rows []string
+		ok   bool
+	}{
+		{"ordinary", column(".", 80), true},
+		{"hard", column("h", 80), false},
+		{"short and hard", column("h", 40), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := newTestWorld(t, &script{}, tc.rows...)
+			d := DifficultyNormal
+			d.MinJumpDist, d.MaxJumpDist = 0, 0
+			w.SetDifficulty(&d)
+			route, ok := w.Solve()
+			if ok != tc.ok {
+				t.Fatalf("found a route: %v, want %v", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			first, last := route.Steps[0], route.Steps[len(route.Steps)-1]
+			if first.Grip != MaxGrip || last.Grip <= 0 || last.Grip >= first.Grip {
+				t.Errorf("route starts with %.2f grip and ends with %.2f, want it to use some but not all", first.Grip, last.Grip)
+			}
+		})
+	}
+}
+
+func TestSolveUnreachableFinish(t *testing.T) {
+	_, route, ok := solveLevel(t,
+		"#####",
+		"#F#.#",
+		"###.#",
+		"#...#",
+		"#S..#",
+		"#####",
+	)
+	if ok {
+		t.Errorf("found a route into the walled off finish: %v", route.Steps)
+	}
+}
+
+func TestSolveLevel0(t *testing.T) {
+	file, err := os.Open("../assets/maps/Project scale.ldtk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	project, err := ReadProject(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	level := project.LevelByIdentifier("Level_0")
+	if level == nil {
+		t.Fatal("the map has no Level_0")
+	}
+
+	w, err := NewWorld(level, loadFrameTags(t), &TickClock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	route, ok := w.Solve()
+	if !ok {
+		t.Fatal("Level_0 can't be finished")
+	}
+	if route.Ticks <= 0 || len(route.Steps) < 2 {
+		t.Errorf("route to the finish of Level_0 takes %d ticks over %d tiles", route.Ticks, len(route.Steps))
+	}
+}
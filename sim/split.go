@@ -0,0 +1,79 @@
+package sim
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/solarlune/ldtkgo"
+)
+
+// Heights in metres where the split times are taken in a level without Split
+// entities or Split_heights
+var splitHeights = []int{250, 500, 750}
+
+// Split is a height on the way up a level where a speedrunner's time is taken
+type Split struct {
+	Name  string
+	Level float64 // Height in the level in pixels the player has to climb to
+}
+
+// ReadSplits reads where the split times of a level are taken from its Split
+// entities, or the heights in metres of its Split_heights field, lowest first
+// and always ending with the finish
+func ReadSplits(level *ldtkgo.Level, startY int) []Split {
+	var splits []Split
+	entities := level.LayerByIdentifier(LayerEntities)
+	for _, entity := range entities.Entities {
+		if entity.Identifier != EntitySplit {
+			continue
+		}
+		y := float64(entity.Position[1] + entity.Height/2)
+		name := fmt.Sprintf("%d m", GetScoreFromY(int(y), startY))
+		if prop := entity.PropertyByIdentifier("Name"); prop != nil && !prop.IsNull() && prop.AsString() != "" {
+			name = prop.AsString()
+		}
+		splits = append(splits, Split{Name: name, Level: y})
+	}
+
+	if len(splits) == 0 {
+		heights := splitHeights
+		if prop := level.PropertyByIdentifier("Split_heights"); prop != nil && !prop.IsNull() {
+			heights = nil
+			for _, h := range prop.AsArray() {
+				if h, ok := h.(float64); ok {
+					heights = append(heights, int(h))
+				}
+			}
+		}
+		for _, h := range heights {
+			splits = append(splits, Split{Name: fmt.Sprintf("%d m", h), Level: GetYFromScore(h, startY)})
+		}
+	}
+
+	slices.SortStableFunc(splits, func(a, b Split) int { return cmp.Compare(b.Level, a.Level) })
+
+	finish := entities.EntityByIdentifier(EntityFinish)
+	return append(splits, Split{Name: "Finish", Level: float64(finish.Position[1])})
+}
+
+// checkSplits takes the time of every split the player climbed to since the
+// last tick, the finish's time is taken when it's reached
+func (w *World) checkSplits() {
+	p := w.Player
+	for len(w.SplitTimes) < len(w.Splits)-1 && p.Position.Y <= w.Splits[len(w.SplitTimes)].Level {
+		w.SplitTimes = append(w.SplitTimes, w.Elapsed())
+		w.Events = append(w.Events, EventSplit)
+	}
+}
+
+// SplitDelta is how much slower a split time is than the same split of a
+// previous round, negative if it's faster, false if there's nothing to compare
+// it with because the splits were different
+func SplitDelta(times, best []time.Duration, i int) (time.Duration, bool) {
+	if i >= len(times) || i >= len(best) {
+		return 0, false
+	}
+	return times[i] - best[i], true
+}
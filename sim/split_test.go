@@ -0,0 +1,116 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/solarlune/ldtkgo"
+)
+
+func TestReadSplits(t *testing.T) {
+	// The finish is at the top of the level
+	rows := []string{"#F#", "#.#", "#-#", "#.#", "#-#", "#.#", "#S#"}
+	named := testLevel(rows...)
+	setField(named, EntitySplit, "Name", "Half way")
+
+	for _, tc := range []struct {
+		name  string
+		level *ldtkgo.Level
+		want  []Split
+	}{
+		{"default heights", testLevel("#F#", "#.#", "#S#"), []Split{
+			{"250 m", 750}, {"500 m", 500}, {"750 m", 250}, {"Finish", 0},
+		}},
+		{"split heights", withField(testLevel("#F#", "#.#", "#S#"), "Split_heights", []any{600.0, 100.0}), []Split{
+			{"100 m", 900}, {"600 m", 400}, {"Finish", 0},
+		}},
+		{"no splits", withField(testLevel("#F#", "#.#", "#S#"), "Split_heights", []any{}), []Split{
+			{"Finish", 0},
+		}},
+		{"entities", testLevel(rows...), []Split{
+			{"928 m", 72}, {"960 m", 40}, {"Finish", 0},
+		}},
+		{"named entities", named, []Split{
+			{"Half way", 72}, {"Half way", 40}, {"Finish", 0},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ReadSplits(tc.level, 1000); !slices.Equal(got, tc.want) {
+				t.Errorf("splits %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// withField sets a field of a level built by testLevel
+func withField(level *ldtkgo.Level, name string, value any) *ldtkgo.Level {
+	level.Properties = append(level.Properties, &ldtkgo.Property{Identifier: name, Value: value})
+	return level
+}
+
+func TestSplitTimes(t *testing.T) {
+	input := &script{presses: []press{{ActionMoveUp, 0, 200}}}
+	w := newTestWorld(t, input,
+		"#F#",
+		"#.#",
+		"#-#",
+		"#.#",
+		"#.#",
+		"#-#",
+		"#.#",
+		"#S#",
+	)
+	if len(w.Splits) != 3 {
+		t.Fatalf("level has splits %v, want two and the finish", w.Splits)
+	}
+
+	tick := time.Second / TPS
+	var ticks []time.Duration
+	for i := 1; i <= 200 && w.Player.State != StateWinning; i++ {
+		w.Step()
+		if slices.Contains(w.Events, EventSplit) {
+			ticks = append(ticks, time.Duration(i)*tick)
+			if split := w.Splits[len(ticks)-1]; w.Player.Position.Y > split.Level {
+				t.Errorf("took split %d at %.1f below it at %.1f", len(ticks)-1, w.Player.Position.Y, split.Level)
+			}
+		}
+	}
+	if w.Player.State != StateWinning {
+		t.Fatal("didn't reach the finish")
+	}
+	// The finish's time is taken without a split event
+	if len(ticks) != 2 || !slices.Equal(w.SplitTimes[:2], ticks) || len(w.SplitTimes) != 3 || w.SplitTimes[2] != w.Elapsed() {
+		t.Errorf("split times %v with split events at %v, want two splits and the finish at %v", w.SplitTimes, ticks, w.Elapsed())
+	}
+
+	// Starting over takes them again
+	w.Reset()
+	if len(w.SplitTimes) != 0 {
+		t.Errorf("split times %v after a reset, want none", w.SplitTimes)
+	}
+}
+
+func TestSplitDelta(t *testing.T) {
+	s := time.Second
+	best := []time.Duration{10 * s, 20 * s, 30 * s}
+	for _, tc := range []struct {
+		name  string
+		times []time.Duration
+		best  []time.Duration
+		i     int
+		want  time.Duration
+		ok    bool
+	}{
+		{"slower", []time.Duration{12 * s}, best, 0, 2 * s, true},
+		{"faster", []time.Duration{10 * s, 18 * s}, best, 1, -2 * s, true},
+		{"not taken yet", []time.Duration{10 * s}, best, 1, 0, false},
+		{"no best", []time.Duration{10 * s}, nil, 0, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, ok := SplitDelta(tc.times, tc.best, tc.i); got != tc.want || ok != tc.ok {
+				t.Errorf("SplitDelta = %v, %v, want %v, %v", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
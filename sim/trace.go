@@ -0,0 +1,70 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package sim
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// tracePointSize is how many bytes a TracePoint takes up when encoded
+const tracePointSize = 10
+
+// TracePoint is where the player was and how they looked during a tick
+type TracePoint struct {
+	X, Y   float32
+	Frame  uint8 // Sprite frame
+	Facing uint8 // Direction
+}
+
+// Trace is the path the player took through a round, one point per tick, so
+// it can be shown again later
+type Trace []TracePoint
+
+// Add appends the player's current position and looks to the trace
+func (t *Trace) Add(p *Player) {
+	*t = append(*t, TracePoint{
+		X:      float32(p.Position.X),
+		Y:      float32(p.Position.Y),
+		Frame:  uint8(p.Frame),
+		Facing: uint8(p.Facing),
+	})
+}
+
+// At returns the point at a tick and whether the trace reaches that far
+func (t Trace) At(tick int) (TracePoint, bool) {
+	if tick < 0 || tick >= len(t) {
+		return TracePoint{}, false
+	}
+	return t[tick], true
+}
+
+// MarshalBinary encodes the trace with a fixed size for each point
+func (t Trace) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len(t)*tracePointSize)
+	for _, point := range t {
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(point.X))
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(point.Y))
+		b = append(b, point.Frame, point.Facing)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a trace encoded by MarshalBinary
+func (t *Trace) UnmarshalBinary(data []byte) error {
+	if len(data)%tracePointSize != 0 {
+		return errors.New("corrupt trace")
+	}
+	*t = make(Trace, 0, len(data)/tracePointSize)
+	for ; len(data) > 0; data = data[tracePointSize:] {
+		*t = append(*t, TracePoint{
+			X:      math.Float32frombits(binary.LittleEndian.Uint32(data[0:])),
+			Y:      math.Float32frombits(binary.LittleEndian.Uint32(data[4:])),
+			Frame:  data[8],
+			Facing: data[9],
+		})
+	}
+	return nil
+}
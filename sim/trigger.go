@@ -0,0 +1,39 @@
+package sim
+
+import (
+	"github.com/solarlune/ldtkgo"
+	"github.com/solarlune/resolv"
+)
+
+// Default surge of a water trigger if the level designer didn't set one
+const (
+	surgeSpeed = 2.0
+	surgeTicks = 60
+)
+
+// WaterTrigger is a place in the level that makes the water surge up the first
+// time the player gets there in a round
+type WaterTrigger struct {
+	*resolv.Object
+	Speed     float64 // Pixels per tick the water rises faster by during the surge
+	Ticks     int     // How long the surge lasts
+	Triggered bool    // Whether the player set it off this round
+}
+
+// NewWaterTrigger creates a water trigger and its collision object from an
+// LDtk entity
+func NewWaterTrigger(entity *ldtkgo.Entity) *WaterTrigger {
+	t := &WaterTrigger{
+		Object: newEntityObject(entity, TagTrigger),
+		Speed:  surgeSpeed,
+		Ticks:  surgeTicks,
+	}
+	if prop := entity.PropertyByIdentifier("Speed"); prop != nil && !prop.IsNull() {
+		t.Speed = prop.AsFloat64()
+	}
+	if prop := entity.PropertyByIdentifier("Ticks"); prop != nil && !prop.IsNull() {
+		t.Ticks = prop.AsInt()
+	}
+	t.Object.Data = t
+	return t
+}
@@ -0,0 +1,196 @@
+package sim
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/solarlune/ldtkgo"
+)
+
+// WaterSpeed is how much the water rises every tick on normal difficulty
+const WaterSpeed = 0.35
+
+// Default ticks for the tide to go up and back down if the level designer
+// didn't set it
+const tidePeriod = 600
+
+// Water is the rising tide the player is escaping from
+type Water struct {
+	Level      float64 // Where the surface is, tide included
+	StartLevel float64
+	Paused     bool
+	Ticks      int         // How long it's been going for this round
+	Difficulty *Difficulty // How fast it rises
+	Curve      WaterCurve  // How fast it rises in this level
+	Tide       float64     // Pixels the tide moves the surface down by right now, up if negative
+	NextPause  int         // Index of the next pause of the curve to reach
+	PauseLeft  int         // Ticks until it starts rising again after a pause
+	SurgeSpeed float64     // Pixels per tick it rises faster by while surging
+	SurgeLeft  int         // Ticks until the surge ends
+}
+
+// WaterCurve is how the water of a level rises on the way up, set by the level
+// designer in the level's fields in LDtk
+type WaterCurve struct {
+	Keys          []WaterKey   // Speeds by height, lowest first
+	Pauses        []WaterPause // Where the water stops for a while, lowest first
+	TideAmplitude float64      // Pixels the tide lifts and lowers the water by
+	TidePeriod    int          // Ticks for the tide to go up and back down
+}
+
+// WaterKey is how fast the water rises once it gets to a height, it changes
+// smoothly from one key to the next
+type WaterKey struct {
+	Level float64 // Height of the water in the level in pixels
+	Speed float64 // How many times the difficulty's speed it rises at
+}
+
+// WaterPause stops the water for a while when it gets to a height
+type WaterPause struct {
+	Level float64 // Height of the water in the level in pixels
+	Ticks int
+}
+
+func NewWater(startLevel float64) *Water {
+	return &Water{
+		Level:      startLevel,
+		StartLevel: startLevel,
+		Difficulty: &DifficultyNormal,
+		Curve:      WaterCurve{TidePeriod: tidePeriod},
+	}
+}
+
+// ReadWaterCurve reads how the water rises from the fields of a level, heights
+// are in metres from the given start position like the player's score
+func ReadWaterCurve(level *ldtkgo.Level, startY int) (WaterCurve, error) {
+	c := WaterCurve{TidePeriod: tidePeriod}
+	toLevel := func(metres any) float64 {
+		m, _ := metres.(float64)
+		return GetYFromScore(int(m), startY)
+	}
+
+	heights, speeds := levelArray(level, "Water_heights"), levelArray(level, "Water_speeds")
+	if len(heights) != len(speeds) {
+		return c, fmt.Errorf("level %s has %d Water_heights but %d Water_speeds", level.Identifier, len(heights), len(speeds))
+	}
+	for i := range heights {
+		speed, _ := speeds[i].(float64)
+		c.Keys = append(c.Keys, WaterKey{Level: toLevel(heights[i]), Speed: speed})
+	}
+
+	heights, ticks := levelArray(level, "Water_pause_heights"), levelArray(level, "Water_pause_ticks")
+	if len(heights) != len(ticks) {
+		return c, fmt.Errorf("level %s has %d Water_pause_heights but %d Water_pause_ticks", level.Identifier, len(heights), len(ticks))
+	}
+	for i := range heights {
+		t, _ := ticks[i].(float64)
+		c.Pauses = append(c.Pauses, WaterPause{Level: toLevel(heights[i]), Ticks: int(t)})
+	}
+
+	// Lowest first, which is furthest down the level
+	slices.SortStableFunc(c.Keys, func(a, b WaterKey) int { return cmp.Compare(b.Level, a.Level) })
+	slices.SortStableFunc(c.Pauses, func(a, b WaterPause) int { return cmp.Compare(b.Level, a.Level) })
+
+	if prop := level.PropertyByIdentifier("Tide_amplitude"); prop != nil && !prop.IsNull() {
+		c.TideAmplitude = prop.AsFloat64()
+	}
+	if prop := level.PropertyByIdentifier("Tide_period"); prop != nil && !prop.IsNull() && prop.AsInt() > 0 {
+		c.TidePeriod = prop.AsInt()
+	}
+	return c, nil
+}
+
+// levelArray is the value of an array field of a level, empty if it isn't set
+func levelArray(level *ldtkgo.Level, name string) []any {
+	if prop := level.PropertyByIdentifier(name); prop != nil && !prop.IsNull() {
+		return prop.AsArray()
+	}
+	return nil
+}
+
+// Speed is how many times the difficulty's speed the water rises at when it's
+// at a level
+func (c *WaterCurve) Speed(level float64) float64 {
+	if len(c.Keys) == 0 {
+		return 1
+	}
+	if level >= c.Keys[0].Level {
+		return c.Keys[0].Speed
+	}
+	for i := 1; i < len(c.Keys); i++ {
+		a, b := c.Keys[i-1], c.Keys[i]
+		if level >= b.Level {
+			t := (a.Level - level) / (a.Level - b.Level)
+			return a.Speed + (b.Speed-a.Speed)*t
+		}
+	}
+	return c.Keys[len(c.Keys)-1].Speed
+}
+
+// Update raises the water, or quickly lowers it back down when it shouldn't
+// be rising any more
+func (w *Water) Update(increaseWaterLevel bool) {
+	if w.Paused {
+		return
+	}
+
+	level := w.Level - w.Tide
+	switch {
+	case !increaseWaterLevel:
+		level += 8.0 * w.Difficulty.WaterSpeed
+	case w.PauseLeft > 0:
+		w.PauseLeft--
+	default:
+		level -= w.Difficulty.WaterSpeedAt(w.Ticks, w.StartLevel-level) * w.Curve.Speed(level)
+	}
+	if w.SurgeLeft > 0 && increaseWaterLevel {
+		level -= w.SurgeSpeed
+		w.SurgeLeft--
+	}
+	if w.NextPause < len(w.Curve.Pauses) && level <= w.Curve.Pauses[w.NextPause].Level {
+		w.PauseLeft = w.Curve.Pauses[w.NextPause].Ticks
+		w.NextPause++
+	}
+	if level > w.StartLevel {
+		level = w.StartLevel
+	}
+
+	w.Ticks++
+	w.Tide = -w.Curve.TideAmplitude * math.Sin(2*math.Pi*float64(w.Ticks)/float64(w.Curve.TidePeriod))
+	w.Level = level + w.Tide
+}
+
+// Surge makes the water rise faster for a while
+func (w *Water) Surge(speed float64, ticks int) {
+	w.SurgeSpeed, w.SurgeLeft = speed, ticks
+}
+
+// LowerTo drops the water down to a level, starting its rise over from there
+// with the pauses above it still to come, water that's already lower stays
+// where it is
+func (w *Water) LowerTo(level float64) {
+	level = min(max(level, w.Level-w.Tide), w.StartLevel)
+	w.Level = level
+	w.Ticks = 0
+	w.Tide = 0
+	w.PauseLeft = 0
+	w.SurgeLeft = 0
+	w.NextPause = len(w.Curve.Pauses)
+	for i, pause := range w.Curve.Pauses {
+		if pause.Level < level {
+			w.NextPause = i
+			break
+		}
+	}
+}
+
+// Reset puts the water back where it was at the start of the level
+func (w *Water) Reset() {
+	w.Level = w.StartLevel
+	w.Ticks = 0
+	w.Tide = 0
+	w.NextPause, w.PauseLeft = 0, 0
+	w.SurgeLeft = 0
+}
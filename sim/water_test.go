@@ -0,0 +1,130 @@
+package sim
+
+import (
+	"math"
+	"testing"
+
+	"github.com/solarlune/ldtkgo"
+)
+
+func TestWaterCurveSpeed(t *testing.T) {
+	curve := WaterCurve{Keys: []WaterKey{{Level: 1000, Speed: 1}, {Level: 600, Speed: 3}, {Level: 200, Speed: 0.5}}}
+	for _, tc := range []struct {
+		name  string
+		curve WaterCurve
+		level float64
+		want  float64
+	}{
+		{"no keys", WaterCurve{}, 500, 1},
+		{"below the first key", curve, 1200, 1},
+		{"at the first key", curve, 1000, 1},
+		{"between keys", curve, 800, 2},
+		{"at a key", curve, 600, 3},
+		{"between the last keys", curve, 300, 1.125},
+		{"above the last key", curve, 0, 0.5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.curve.Speed(tc.level); math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("speed at %.0f is %.3f, want %.3f", tc.level, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadWaterCurve(t *testing.T) {
+	level := testLevel("#F#", "#.#", "#S#")
+	field := func(name string, values ...any) *ldtkgo.Property {
+		return &ldtkgo.Property{Identifier: name, Value: values}
+	}
+	startY := 1000
+	level.Properties = []*ldtkgo.Property{
+		field("Water_heights", 500.0, 100.0),
+		field("Water_speeds", 2.0, 1.0),
+		field("Water_pause_heights", 750.0, 250.0),
+		field("Water_pause_ticks", 60.0, 30.0),
+		{Identifier: "Tide_amplitude", Value: 4.0},
+	}
+	curve, err := ReadWaterCurve(level, startY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Lowest first, further down the level is a larger Y
+	wantKeys := []WaterKey{{Level: 900, Speed: 1}, {Level: 500, Speed: 2}}
+	wantPauses := []WaterPause{{Level: 750, Ticks: 30}, {Level: 250, Ticks: 60}}
+	if len(curve.Keys) != 2 || curve.Keys[0] != wantKeys[0] || curve.Keys[1] != wantKeys[1] {
+		t.Errorf("keys %v, want %v", curve.Keys, wantKeys)
+	}
+	if len(curve.Pauses) != 2 || curve.Pauses[0] != wantPauses[0] || curve.Pauses[1] != wantPauses[1] {
+		t.Errorf("pauses %v, want %v", curve.Pauses, wantPauses)
+	}
+	if curve.TideAmplitude != 4 || curve.TidePeriod != tidePeriod {
+		t.Errorf("tide of %.0f pixels every %d ticks, want 4 pixels every %d", curve.TideAmplitude, curve.TidePeriod, tidePeriod)
+	}
+
+	level.Properties[1] = field("Water_speeds", 2.0)
+	if _, err := ReadWaterCurve(level, startY); err == nil {
+		t.Error("read a water speed for every height with one missing")
+	}
+}
+
+func TestWaterPause(t *testing.T) {
+	w := NewWater(1000)
+	w.Curve.Pauses = []WaterPause{{Level: 990, Ticks: 30}, {Level: 980, Ticks: 10}}
+
+	// Rises to the first pause in 29 ticks at 0.35 pixels a tick
+	ticks := 0
+	for w.PauseLeft == 0 {
+		w.Update(true)
+		ticks++
+	}
+	if ticks != 29 || w.NextPause != 1 {
+		t.Errorf("reached the pause after %d ticks with pause %d next, want after 29 with pause 1 next", ticks, w.NextPause)
+	}
+
+	level := w.Level
+	for range 30 {
+		w.Update(true)
+	}
+	if w.Level != level {
+		t.Errorf("water moved from %.2f to %.2f during the pause", level, w.Level)
+	}
+	w.Update(true)
+	if w.Level >= level {
+		t.Errorf("water at %.2f after the pause, want it rising from %.2f", w.Level, level)
+	}
+}
+
+func TestWaterTideAndSurge(t *testing.T) {
+	still := DifficultyNormal
+	still.WaterSpeed = 0
+
+	w := NewWater(1000)
+	w.Difficulty = &still
+	w.Curve.TideAmplitude = 8
+	for range tidePeriod / 4 {
+		w.Update(true)
+	}
+	if math.Abs(w.Level-(1000-8)) > 1e-9 {
+		t.Errorf("water at %.2f at high tide, want 8 pixels up at 992", w.Level)
+	}
+
+	w = NewWater(1000)
+	w.Difficulty = &still
+	w.Surge(2, 10)
+	for range 20 {
+		w.Update(true)
+	}
+	if w.Level != 980 || w.SurgeLeft != 0 {
+		t.Errorf("water at %.2f with %d ticks of surge left, want it surged up to 980", w.Level, w.SurgeLeft)
+	}
+
+	// The water sinks instead once the round is over, eight times as fast as
+	// it rises and never below where it started
+	w.Difficulty = &DifficultyNormal
+	for range 20 {
+		w.Update(false)
+	}
+	if w.Level != 1000 {
+		t.Errorf("water at %.2f after the round, want back at 1000", w.Level)
+	}
+}
@@ -0,0 +1,347 @@
+// Use of this source code is subject to an MIT-style
+// licence which can be found in the LICENSE file.
+
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/solarlune/ldtkgo"
+	"github.com/solarlune/resolv"
+)
+
+// GridSize is the size of a map tile in pixels
+const GridSize = 16
+
+// MaxScore is the height you've reached in metres at the top of a level
+const MaxScore = 1000
+
+// Event is something that happened in the simulation during a tick that the
+// game might want to show or play a sound for
+type Event uint8
+
+const (
+	EventJumpEndWall  Event = iota // Jumped into a wall
+	EventCheckpoint                // Reached a checkpoint
+	EventFinish                    // Reached the finish
+	EventSubmerge                  // The water rose over the player
+	EventSplash                    // Fell into the water
+	EventClimbStep                 // Took a step climbing
+	EventJumpEndFloor              // Landed a jump on something climbable
+	EventSlipStart                 // Started slipping
+	EventFallEnd                   // Stopped falling
+	EventGrapple                   // Fired the grappling hook
+	EventCrumble                   // A crumbling tile gave way
+	EventNoGrip                    // Ran out of grip and let go
+	EventSurge                     // The water started surging up
+	EventSplit                     // Climbed to a split, its time was taken
+)
+
+// World is everything in a level that affects the player
+type World struct {
+	Level        *ldtkgo.Level
+	Space        *resolv.Space
+	Player       *Player
+	Water        *Water
+	Difficulty   *Difficulty // How fast the water rises and the player moves
+	Checkpoints  []*Checkpoint
+	Platforms    []*Platform
+	Crumbling    []*CrumblingTile
+	Triggers     []*WaterTrigger
+	Checkpoint   *Checkpoint // Last checkpoint reached, nil if there's none
+	StartPos     []int
+	Clock        Clock
+	Start        time.Time       // When the round started
+	End          time.Time       // When the finish was reached
+	HighestPoint int             // Highest point reached this round
+	Events       []Event         // What happened during the last tick
+	Trace        Trace           // Path the player took this round, one point per tick
+	Splits       []Split         // Where split times are taken, ending with the finish
+	SplitTimes   []time.Duration // Time of each split reached this round
+}
+
+// NewWorld sets up the collision space, entities and water of a level
+func NewWorld(level *ldtkgo.Level, frameTags []FrameTag, clock Clock) (*World, error) {
+	if err := CheckLayers(level); err != nil {
+		return nil, err
+	}
+	if !IsPlayable(level) {
+		return nil, errors.New("level " + level.Identifier + " needs a " + EntityPlayerStart + " and " + EntityFinish)
+	}
+
+	w := &World{Level: level, Clock: clock}
+
+	// Create space for collision detection
+	w.Space = resolv.NewSpace(level.Width, level.Height, GridSize, GridSize)
+
+	// Obstacles
+	for _, layerName := range []string{
+		LayerFloor,
+		LayerWalls,
+		LayerInvisible,
+	} {
+		if err := TilesToObstacles(level.LayerByIdentifier(layerName), w.Space); err != nil {
+			return nil, fmt.Errorf("level %s: %w", level.Identifier, err)
+		}
+	}
+
+	// Finish point
+	entities := level.LayerByIdentifier(LayerEntities)
+	finishPos := entities.EntityByIdentifier(EntityFinish)
+	finish := resolv.NewObject(
+		float64(finishPos.Position[0]), float64(finishPos.Position[1]),
+		float64(finishPos.Width), float64(finishPos.Height),
+		TagFinish,
+	)
+	finish.SetShape(resolv.NewRectangle(
+		0, 0, // origin
+		float64(finishPos.Width), float64(finishPos.Height),
+	))
+	w.Space.Add(finish)
+
+	// Checkpoints, platforms and water triggers
+	for _, entity := range entities.Entities {
+		switch entity.Identifier {
+		case EntityCheckpoint:
+			checkpoint := NewCheckpoint(entity)
+			w.Checkpoints = append(w.Checkpoints, checkpoint)
+			w.Space.Add(checkpoint.Object)
+		case EntityPlatform:
+			platform := NewPlatform(entity)
+			w.Platforms = append(w.Platforms, platform)
+			w.Space.Add(platform.Object)
+		case EntityCrumbling:
+			tile := NewCrumblingTile(entity)
+			w.Crumbling = append(w.Crumbling, tile)
+			w.Space.Add(tile.Object)
+		case EntityTrigger:
+			trigger := NewWaterTrigger(entity)
+			w.Triggers = append(w.Triggers, trigger)
+			w.Space.Add(trigger.Object)
+		}
+	}
+
+	// Player setup
+	startPos := entities.EntityByIdentifier(EntityPlayerStart)
+	w.StartPos = []int{
+		startPos.Position[0] + (startPos.Width / 2),
+		startPos.Position[1] + (startPos.Height / 2),
+	}
+	w.Player = NewPlayer(w.StartPos, frameTags)
+	w.Space.Add(w.Player.Object)
+
+	w.Water = NewWater(float64(level.Height) + 4*w.Player.Size.Y)
+	curve, err := ReadWaterCurve(level, w.StartPos[1])
+	if err != nil {
+		return nil, err
+	}
+	w.Water.Curve = curve
+	w.Splits = ReadSplits(level, w.StartPos[1])
+	w.SetDifficulty(&DifficultyNormal)
+	w.Start = w.Clock.Now()
+
+	return w, nil
+}
+
+// Step advances the simulation by one tick
+func (w *World) Step() {
+	if s, ok := w.Clock.(Stepper); ok {
+		s.Step()
+	}
+	w.Events = w.Events[:0]
+
+	p := w.Player
+	w.updatePlatforms()
+	p.Update()
+	for _, tile := range w.Crumbling {
+		if tile.Update(p) {
+			w.Events = append(w.Events, EventCrumble)
+		}
+	}
+	if t, ok := p.Input.(Ticker); ok {
+		t.Tick()
+	}
+	w.Events = append(w.Events, p.Events...)
+	w.Trace.Add(p)
+
+	if pos := GetScoreFromY(int(p.Position.Y), w.StartPos[1]); pos > w.HighestPoint {
+		w.HighestPoint = pos
+	}
+	if p.State != StateDying && p.State != StateDead {
+		w.checkCheckpoint()
+		w.checkTriggers()
+		w.checkSplits()
+	}
+
+	if p.State != StateWinning && w.checkFinish() {
+		w.End = w.Clock.Now()
+		w.SplitTimes = append(w.SplitTimes, w.End.Sub(w.Start))
+		p.State = StateWinning
+		w.Events = append(w.Events, EventFinish)
+	}
+
+	w.Water.Update(p.State != StateWinning)
+
+	switch p.State {
+	case StateDying, StateWinning:
+	default:
+		if w.checkDeath() {
+			if p.State != StateFalling {
+				w.Events = append(w.Events, EventSubmerge)
+			} else {
+				w.Events = append(w.Events, EventSplash)
+			}
+			p.State = StateDying
+			p.AnimState = PlayerFallloop
+		}
+	}
+}
+
+// Elapsed returns how long the round has taken so far, or took to finish
+func (w *World) Elapsed() time.Duration {
+	if w.Player.State == StateWinning || w.Player.State == StateWon {
+		return w.End.Sub(w.Start)
+	}
+	return w.Clock.Now().Sub(w.Start)
+}
+
+// Reset puts everything back where it was at the start of the level
+func (w *World) Reset() {
+	w.place(float64(w.StartPos[0]), float64(w.StartPos[1]))
+	w.Water.Reset()
+	w.Start = w.Clock.Now()
+	w.HighestPoint = 0
+	w.Trace = nil
+	w.SplitTimes = nil
+	w.Checkpoint = nil
+	for _, checkpoint := range w.Checkpoints {
+		checkpoint.Reached = false
+	}
+	for _, platform := range w.Platforms {
+		platform.Reset()
+	}
+	for _, trigger := range w.Triggers {
+		trigger.Triggered = false
+	}
+	w.resetCrumbling()
+}
+
+// SetDifficulty changes how fast the water rises and the player moves from
+// the next tick on
+func (w *World) SetDifficulty(d *Difficulty) {
+	w.Difficulty = d
+	w.Player.Difficulty = d
+	w.Water.Difficulty = d
+}
+
+// Respawn puts the player back at the last checkpoint they reached and lowers
+// the water to a safe distance below it, the round's clock keeps running.
+// Without a checkpoint the level starts over
+func (w *World) Respawn() {
+	if w.Checkpoint == nil {
+		w.Reset()
+		return
+	}
+	w.place(w.Checkpoint.Center())
+	w.Water.LowerTo(w.Checkpoint.WaterLevel())
+	w.resetCrumbling()
+}
+
+// resetCrumbling puts every crumbling tile back together so that the way up
+// from a checkpoint isn't lost after dying
+func (w *World) resetCrumbling() {
+	for _, tile := range w.Crumbling {
+		tile.Reset()
+	}
+}
+
+// updatePlatforms moves the platforms and the player along with the one
+// they're holding on to
+func (w *World) updatePlatforms() {
+	p := w.Player
+	for _, platform := range w.Platforms {
+		carried := holds(platform.Object, p)
+		move := platform.Update()
+		if carried {
+			p.Position.X += move[0]
+			p.Position.Y += move[1]
+			p.Object.Update()
+		}
+	}
+}
+
+func (w *World) place(x, y float64) {
+	p := w.Player
+	p.Position.X, p.Position.Y = x, y
+	p.Facing = DirectionUp
+	p.AnimState = PlayerIdle
+	p.State = StateIdle
+	p.Rotation = 0
+	p.Grip = MaxGrip
+	p.Object.Update()
+}
+
+// checkCheckpoint remembers the checkpoint the player is touching as the place
+// to respawn after dying
+func (w *World) checkCheckpoint() {
+	p := w.Player
+	if collision := p.Check(0, 0, TagCheckpoint); collision != nil {
+		for _, o := range collision.Objects {
+			if p.Shape.Intersection(0, 0, o.Shape) != nil {
+				checkpoint := o.Data.(*Checkpoint)
+				if checkpoint != w.Checkpoint {
+					w.Events = append(w.Events, EventCheckpoint)
+				}
+				checkpoint.Reached = true
+				w.Checkpoint = checkpoint
+			}
+		}
+	}
+}
+
+// checkTriggers makes the water surge when the player gets to a water trigger
+// for the first time this round
+func (w *World) checkTriggers() {
+	p := w.Player
+	if collision := p.Check(0, 0, TagTrigger); collision != nil {
+		for _, o := range collision.Objects {
+			trigger := o.Data.(*WaterTrigger)
+			if !trigger.Triggered && centreInside(o, p.Position.X, p.Position.Y, p) {
+				trigger.Triggered = true
+				w.Water.Surge(trigger.Speed, trigger.Ticks)
+				w.Events = append(w.Events, EventSurge)
+			}
+		}
+	}
+}
+
+func (w *World) checkFinish() bool {
+	p := w.Player
+	if collision := p.Check(0, 0, TagFinish); collision != nil {
+		for _, o := range collision.Objects {
+			if p.Shape.Intersection(0, 0, o.Shape) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (w *World) checkDeath() bool {
+	// Death by water (water covers the top of you)
+	if w.Water.Level < w.Player.Position.Y-w.Player.Size.Y/4 {
+		return true
+	}
+
+	return false
+}
+
+func GetScoreFromY(position, levelHeight int) int {
+	return int(float64(levelHeight-position) / float64(levelHeight) * float64(MaxScore))
+}
+
+func GetYFromScore(score, levelHeight int) float64 {
+	return float64(levelHeight) - float64(score)/float64(MaxScore)*float64(levelHeight)
+}
@@ -0,0 +1,628 @@
+package sim
+
+import (
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/solarlune/ldtkgo"
+)
+
+// Tiles of the test tileset, each tagged with its TileKind like in LDtk
+const (
+	tileClimbable = iota
+	tileWall
+	tileChasm
+	tileSlippery
+	tileHard
+)
+
+var testTileset = &ldtkgo.Tileset{
+	Identifier: "Test",
+	GridSize:   GridSize,
+	Enums: map[int]ldtkgo.EnumSet{
+		tileClimbable: {"Climbable"},
+		tileWall:      {"Wall"},
+		tileChasm:     {"Chasm"},
+		tileSlippery:  {"Slippery"},
+		tileHard:      {"Climbable_Hard"},
+	},
+}
+
+// testLevel builds a level out of rows of characters, one per tile:
+//
+//	. climbable
+//	# wall
+//	~ chasm
+//	/ slippery
+//	h climbable, hard to grip
+//	S climbable, where the player starts
+//	F climbable, where the finish is
+//	C climbable, with a checkpoint
+//	P chasm, with a moving platform over it
+//	x chasm, with a crumbling tile over it
+//	- climbable, with a split
+//	  nothing
+func testLevel(rows ...string) *ldtkgo.Level {
+	floor := &ldtkgo.Layer{Identifier: LayerFloor, GridSize: GridSize, Tileset: testTileset}
+	walls := &ldtkgo.Layer{Identifier: LayerWalls, GridSize: GridSize, Tileset: testTileset}
+	entities := &ldtkgo.Layer{Identifier: LayerEntities, GridSize: GridSize}
+	level := &ldtkgo.Level{
+		Identifier: "Test",
+		Width:      len(rows[0]) * GridSize,
+		Height:     len(rows) * GridSize,
+		Layers: []*ldtkgo.Layer{
+			entities,
+			floor,
+			walls,
+			{Identifier: LayerInvisible, GridSize: GridSize, Tileset: testTileset},
+		},
+	}
+
+	tile := func(layer *ldtkgo.Layer, id, x, y int) {
+		layer.Tiles = append(layer.Tiles, &ldtkgo.Tile{ID: id, Position: []int{x, y}})
+	}
+	entity := func(name string, x, y int) {
+		entities.Entities = append(entities.Entities, &ldtkgo.Entity{
+			Identifier: name,
+			Position:   []int{x, y},
+			Width:      GridSize,
+			Height:     GridSize,
+		})
+	}
+	for cy, row := range rows {
+		for cx, c := range row {
+			x, y := cx*GridSize, cy*GridSize
+			switch c {
+			case '.':
+				tile(floor, tileClimbable, x, y)
+			case '#':
+				tile(walls, tileWall, x, y)
+			case '~':
+				tile(floor, tileChasm, x, y)
+			case '/':
+				tile(floor, tileSlippery, x, y)
+			case 'h':
+				tile(floor, tileHard, x, y)
+			case 'S':
+				tile(floor, tileClimbable, x, y)
+				entity(EntityPlayerStart, x, y)
+			case 'F':
+				tile(floor, tileClimbable, x, y)
+				entity(EntityFinish, x, y)
+			case 'C':
+				tile(floor, tileClimbable, x, y)
+				entity(EntityCheckpoint, x, y)
+			case 'P':
+				tile(floor, tileChasm, x, y)
+				entity(EntityPlatform, x, y)
+			case 'x':
+				tile(floor, tileChasm, x, y)
+				entity(EntityCrumbling, x, y)
+			case '-':
+				tile(floor, tileClimbable, x, y)
+				entity(EntitySplit, x, y)
+			}
+		}
+	}
+	return level
+}
+
+// setField sets a field of every entity of a kind in a level built by testLevel
+func setField(level *ldtkgo.Level, entity, field string, value any) {
+	for _, e := range level.LayerByIdentifier(LayerEntities).Entities {
+		if e.Identifier == entity {
+			e.Properties = append(e.Properties, &ldtkgo.Property{Identifier: field, Value: value})
+		}
+	}
+}
+
+// loadFrameTags reads the Nanobot's animations, which time some of the
+// player's state changes
+func loadFrameTags(t *testing.T) []FrameTag {
+	t.Helper()
+	data, err := os.ReadFile("../assets/sprites/Nanobot.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := ParseFrameTags(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tags
+}
+
+// newTestWorld sets up a level to be stepped with the given input, timed by
+// a TickClock
+func newTestWorld(t *testing.T, input Input, rows ...string) *World {
+	t.Helper()
+	return newLevelWorld(t, input, testLevel(rows...))
+}
+
+// newLevelWorld sets up a level built by testLevel and changed afterwards
+func newLevelWorld(t *testing.T, input Input, level *ldtkgo.Level) *World {
+	t.Helper()
+	w, err := NewWorld(level, loadFrameTags(t), &TickClock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Player.Input = input
+	return w
+}
+
+// press is an action held down for a number of ticks from a tick on
+type press struct {
+	Action     Action
+	From, Till int // Held from the tick From up to but not including Till
+}
+
+// script is an Input that presses actions on the ticks it's told to
+type script struct {
+	tick    int
+	presses []press
+}
+
+func (s *script) ActionIsPressed(action Action) bool {
+	for _, p := range s.presses {
+		if p.Action == action && s.tick >= p.From && s.tick < p.Till {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *script) ActionIsJustPressed(action Action) bool {
+	for _, p := range s.presses {
+		if p.Action == action && s.tick == p.From {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *script) Tick() {
+	s.tick++
+}
+
+// run steps the world for a number of ticks and returns every event that
+// happened, in order
+func run(w *World, ticks int) []Event {
+	var events []Event
+	for range ticks {
+		w.Step()
+		events = append(events, w.Events...)
+	}
+	return events
+}
+
+// runUntil steps the world until an event happens, for at most a number of
+// ticks, and returns the ticks it took
+func runUntil(t *testing.T, w *World, event Event, ticks int) int {
+	t.Helper()
+	for i := 1; i <= ticks; i++ {
+		w.Step()
+		if slices.Contains(w.Events, event) {
+			return i
+		}
+	}
+	t.Fatalf("no event %d in %d ticks, the player is %s at %.1f, %.1f",
+		event, ticks, PlayerStateNames[w.Player.State], w.Player.Position.X, w.Player.Position.Y)
+	return 0
+}
+
+// tileOf is the tile the centre of the player is on
+func tileOf(p *Player) (int, int) {
+	return int(p.Position.X+p.Size.X/2) / GridSize, int(p.Position.Y+p.Size.Y/2) / GridSize
+}
+
+// placeOn puts the player on a tile holding on, against its left edge like
+// climbThen does
+func placeOn(w *World, x, y int) {
+	w.place(float64(x*GridSize), float64(y*GridSize)+(GridSize-w.Player.Size.Y)/2)
+}
+
+func TestClimb(t *testing.T) {
+	input := &script{presses: []press{{ActionMoveUp, 0, 40}}}
+	w := newTestWorld(t, input,
+		"#F#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#S#",
+	)
+	startY := w.Player.Position.Y
+	events := run(w, 40)
+
+	if want := startY - 40*DifficultyNormal.ClimbSpeed; w.Player.Position.Y > want+0.01 {
+		t.Errorf("climbed to y %.1f, want %.1f", w.Player.Position.Y, want)
+	}
+	if w.Player.State != StateIdle || w.Player.Facing != DirectionUp {
+		t.Errorf("player is %s facing %d, want Idle facing up", PlayerStateNames[w.Player.State], w.Player.Facing)
+	}
+	if !slices.Contains(events, EventClimbStep) {
+		t.Errorf("no climbing steps in %v", events)
+	}
+
+	// Letting go of the key stops the climb
+	y := w.Player.Position.Y
+	run(w, 10)
+	if w.Player.Position.Y != y {
+		t.Errorf("kept climbing from y %.1f to %.1f without input", y, w.Player.Position.Y)
+	}
+}
+
+func TestJump(t *testing.T) {
+	input := &script{presses: []press{{ActionPrimary, 0, 60}}}
+	w := newTestWorld(t, input,
+		"#F#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#S#",
+	)
+	startY := w.Player.Position.Y
+	ticks := runUntil(t, w, EventJumpEndFloor, 60)
+
+	jumped := startY - w.Player.Position.Y
+	if jumped < DifficultyNormal.MaxJumpDist || jumped > DifficultyNormal.MaxJumpDist+DifficultyNormal.JumpSpeed {
+		t.Errorf("jumped %.1f pixels in %d ticks holding the button, want %.1f", jumped, ticks, DifficultyNormal.MaxJumpDist)
+	}
+	if w.Player.State != StateJumping {
+		t.Errorf("player is %s landing the jump", PlayerStateNames[w.Player.State])
+	}
+	run(w, 30)
+	if w.Player.State != StateIdle {
+		t.Errorf("player is %s after landing, want Idle", PlayerStateNames[w.Player.State])
+	}
+}
+
+func TestShortJump(t *testing.T) {
+	input := &script{presses: []press{{ActionPrimary, 0, 1}}}
+	w := newTestWorld(t, input,
+		"#F#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#S#",
+	)
+	startY := w.Player.Position.Y
+	runUntil(t, w, EventJumpEndFloor, 60)
+
+	jumped := startY - w.Player.Position.Y
+	if jumped < DifficultyNormal.MinJumpDist || jumped >= DifficultyNormal.MaxJumpDist {
+		t.Errorf("tapping jump went %.1f pixels, want %.1f", jumped, DifficultyNormal.MinJumpDist)
+	}
+}
+
+// climbThen climbs left and up a little before holding an action facing up,
+// so that the player doesn't land lined up with the edges of the tiles, which
+// collision checks don't count as touching
+func climbThen(action Action, hold int) *script {
+	return &script{presses: []press{
+		{ActionMoveLeft, 0, 5},
+		{ActionMoveUp, 5, 10},
+		{action, 10, 10 + hold},
+	}}
+}
+
+// climbThenJump climbs a little before jumping up, see climbThen
+func climbThenJump(hold int) *script {
+	return climbThen(ActionPrimary, hold)
+}
+
+func TestJumpOverChasm(t *testing.T) {
+	w := newTestWorld(t, climbThenJump(60),
+		"#F#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#~#",
+		"#~#",
+		"#S#",
+	)
+	events := run(w, 90)
+	if slices.Contains(events, EventFallEnd) || w.Player.State != StateIdle {
+		t.Errorf("player is %s after jumping over the chasm: %v", PlayerStateNames[w.Player.State], events)
+	}
+	if _, y := tileOf(w.Player); y > 3 {
+		t.Errorf("landed on tile %d, want above the chasm", y)
+	}
+}
+
+func TestChasmFall(t *testing.T) {
+	// A short jump lands in the chasm and the player falls back down it
+	w := newTestWorld(t, climbThenJump(1),
+		"#F#",
+		"#.#",
+		"#.#",
+		"#~#",
+		"#~#",
+		"#.#",
+		"#S#",
+	)
+	fell := false
+	var events []Event
+	for range 120 {
+		w.Step()
+		events = append(events, w.Events...)
+		fell = fell || w.Player.State == StateFalling
+	}
+	if !fell || !slices.Contains(events, EventFallEnd) {
+		t.Fatalf("didn't fall landing in the chasm: %v", events)
+	}
+	if slices.Contains(events, EventJumpEndFloor) {
+		t.Errorf("landed the jump on the chasm: %v", events)
+	}
+	if _, y := tileOf(w.Player); y != 5 {
+		t.Errorf("stopped falling on tile %d, want 5 below the chasm", y)
+	}
+	if w.Player.State != StateIdle {
+		t.Errorf("player is %s after the fall, want Idle", PlayerStateNames[w.Player.State])
+	}
+}
+
+// grappleLevel has a chasm too wide to jump over between the start and a
+// climbable tile within reach of the hook
+var grappleLevel = []string{
+	"#F#",
+	"#.#",
+	"#.#",
+	"#~#",
+	"#~#",
+	"#~#",
+	"#~#",
+	"#S#",
+	"#.#",
+	"#.#",
+	"#.#",
+}
+
+func TestGrapple(t *testing.T) {
+	w := newTestWorld(t, climbThen(ActionSecondary, 60), grappleLevel...)
+	fell := false
+	var events []Event
+	for range 70 {
+		w.Step()
+		events = append(events, w.Events...)
+		fell = fell || w.Player.State == StateFalling
+	}
+	if !slices.Contains(events, EventGrapple) || fell {
+		t.Fatalf("didn't reel in across the chasm: %v", events)
+	}
+	if _, y := tileOf(w.Player); y != 2 {
+		t.Errorf("reeled in to tile %d, want 2 above the chasm", y)
+	}
+	if w.Player.State != StateIdle {
+		t.Errorf("player is %s after reeling in, want Idle", PlayerStateNames[w.Player.State])
+	}
+}
+
+func TestGrappleRelease(t *testing.T) {
+	input := climbThen(ActionSecondary, 600)
+	w := newTestWorld(t, input, grappleLevel...)
+	startY := w.Player.Position.Y
+	for w.Player.Position.Y > startY-2*GridSize {
+		if input.tick == 60 {
+			t.Fatal("the grapple didn't start reeling in")
+		}
+		w.Step()
+	}
+
+	// Letting go halfway across stops the reel and the player falls
+	input.presses[2].Till = input.tick
+	y := w.Player.Position.Y
+	w.Step()
+	if moved := y - w.Player.Position.Y; moved > speedGrapple {
+		t.Errorf("moved %.1f pixels the tick the button was let go, want at most %.1f", moved, speedGrapple)
+	}
+	runUntil(t, w, EventFallEnd, 120)
+	if _, y := tileOf(w.Player); y != 7 {
+		t.Errorf("stopped falling on tile %d, want 7 below the chasm", y)
+	}
+}
+
+func TestGrappleMiss(t *testing.T) {
+	// Nothing to catch on within range
+	w := newTestWorld(t, climbThen(ActionSecondary, 60),
+		"#F#",
+		"#~#",
+		"#~#",
+		"#~#",
+		"#~#",
+		"#~#",
+		"#~#",
+		"#~#",
+		"#S#",
+	)
+	run(w, 10)
+	x, y := w.Player.Position.X, w.Player.Position.Y
+	events := run(w, 60)
+	if slices.Contains(events, EventGrapple) || w.Player.State != StateIdle {
+		t.Errorf("player is %s after firing the hook at nothing: %v", PlayerStateNames[w.Player.State], events)
+	}
+	if w.Player.Position.X != x || w.Player.Position.Y != y {
+		t.Errorf("moved to %.1f, %.1f firing the hook at nothing, want to stay at %.1f, %.1f", w.Player.Position.X, w.Player.Position.Y, x, y)
+	}
+}
+
+func TestFinish(t *testing.T) {
+	input := &script{presses: []press{{ActionMoveUp, 0, 200}}}
+	w := newTestWorld(t, input,
+		"#F#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#.#",
+		"#S#",
+	)
+	ticks := runUntil(t, w, EventFinish, 200)
+
+	if w.Player.State != StateWinning {
+		t.Errorf("player is %s at the finish, want Winning", PlayerStateNames[w.Player.State])
+	}
+	if _, y := tileOf(w.Player); y > 1 {
+		t.Errorf("finished on tile %d, want to touch the finish on tile 0", y)
+	}
+	want := time.Duration(ticks) * (time.Second / TPS)
+	if w.Elapsed() != want {
+		t.Errorf("round took %v, want %v for %d ticks", w.Elapsed(), want, ticks)
+	}
+	if len(w.SplitTimes) != len(w.Splits) || w.SplitTimes[len(w.SplitTimes)-1] != want {
+		t.Errorf("split times %v, want the last one at the finish %v", w.SplitTimes, want)
+	}
+
+	// The clock stops at the finish
+	run(w, 30)
+	if w.Elapsed() != want {
+		t.Errorf("round took %v after finishing, want it to stay %v", w.Elapsed(), want)
+	}
+}
+
+func TestDrown(t *testing.T) {
+	w := newTestWorld(t, &script{},
+		"#F#",
+		"#.#",
+		"#.#",
+		"#S#",
+	)
+	startX, startY := w.Player.Position.X, w.Player.Position.Y
+	ticks := runUntil(t, w, EventSubmerge, 600)
+
+	// The water starts four player heights below the level
+	risen := w.Water.StartLevel - w.Water.Level
+	if want := float64(ticks) * WaterSpeed; risen < want-0.01 || risen > want+0.01 {
+		t.Errorf("water rose %.1f pixels in %d ticks, want %.1f", risen, ticks, want)
+	}
+	if w.Water.Level >= w.Player.Position.Y-w.Player.Size.Y/4 {
+		t.Errorf("submerged with the water at %.1f below the player at %.1f", w.Water.Level, w.Player.Position.Y)
+	}
+	if w.Player.State != StateDying {
+		t.Errorf("player is %s under water, want Dying", PlayerStateNames[w.Player.State])
+	}
+	if w.Player.Position.X != startX || w.Player.Position.Y != startY {
+		t.Errorf("player moved from %.1f, %.1f to %.1f, %.1f without input", startX, startY, w.Player.Position.X, w.Player.Position.Y)
+	}
+
+	// Dying sinks the player
+	run(w, 10)
+	if w.Player.Position.Y <= startY {
+		t.Errorf("player at y %.1f didn't sink below %.1f", w.Player.Position.Y, startY)
+	}
+}
+
+func TestSplash(t *testing.T) {
+	// Falling into the water is a splash instead of being submerged
+	w := newTestWorld(t, climbThenJump(1),
+		"#F#",
+		"#.#",
+		"#.#",
+		"#~#",
+		"#~#",
+		"#.#",
+		"#S#",
+	)
+	for w.Player.State != StateFalling {
+		if w.Step(); w.Player.Tick > 120 {
+			t.Fatal("didn't fall landing in the chasm")
+		}
+	}
+	// Just below where the player lands at the bottom of the chasm
+	w.Water.Level = 5 * GridSize
+	w.Water.Paused = true
+	events := run(w, 60)
+	if !slices.Contains(events, EventSplash) || slices.Contains(events, EventSubmerge) {
+		t.Errorf("events %v, want a splash falling into the water", events)
+	}
+	if w.Player.State != StateDying {
+		t.Errorf("player is %s in the water, want Dying", PlayerStateNames[w.Player.State])
+	}
+}
+
+// respawnLevel has a checkpoint right above the start and room below it for
+// the water to be lowered to
+var respawnLevel = []string{
+	"#F#",
+	"#C#",
+	"#S#",
+	"#.#",
+	"#.#",
+	"#.#",
+	"#.#",
+	"#.#",
+	"#.#",
+	"#.#",
+	"#.#",
+	"#.#",
+	"#.#",
+}
+
+// reachCheckpoint climbs up to the checkpoint of the respawn level
+func reachCheckpoint(t *testing.T) *World {
+	t.Helper()
+	w := newTestWorld(t, &script{presses: []press{{ActionMoveUp, 0, 20}}}, respawnLevel...)
+	runUntil(t, w, EventCheckpoint, 20)
+	return w
+}
+
+func TestRespawn(t *testing.T) {
+	w := reachCheckpoint(t)
+
+	// One pause below where the water is lowered to and one above
+	safe := w.Checkpoint.WaterLevel()
+	w.Water.Curve.Pauses = []WaterPause{
+		{Level: safe + GridSize, Ticks: 30},
+		{Level: safe - GridSize, Ticks: 30},
+	}
+	w.Water.Level = safe - 2*GridSize
+	w.Water.Ticks, w.Water.Tide = 500, 3
+	w.Water.NextPause, w.Water.PauseLeft = 2, 20
+
+	w.Respawn()
+	water := w.Water
+	if water.Level != safe || water.NextPause != 1 || water.PauseLeft != 0 || water.Tide != 0 || water.Ticks != 0 {
+		t.Errorf("water at %.1f with pause %d, %d ticks left, tide %.1f after %d ticks, want at %.1f with pause 1 next and nothing else left",
+			water.Level, water.NextPause, water.PauseLeft, water.Tide, water.Ticks, safe)
+	}
+
+	// The water stops again at the pause above the checkpoint's safe height
+	for range 600 {
+		water.Update(true)
+		if water.PauseLeft > 0 {
+			break
+		}
+	}
+	if water.NextPause != 2 || water.PauseLeft != 30 {
+		t.Errorf("water at %.1f with pause %d next and %d ticks left, want it stopped at %.1f", water.Level, water.NextPause, water.PauseLeft, safe-GridSize)
+	}
+}
+
+func TestRespawnLowWater(t *testing.T) {
+	// Water that's already below the checkpoint's safe height isn't raised
+	w := reachCheckpoint(t)
+	low := w.Checkpoint.WaterLevel() + GridSize
+	w.Water.Level = low
+	w.Respawn()
+	if w.Water.Level != low {
+		t.Errorf("respawning moved the water from %.1f to %.1f", low, w.Water.Level)
+	}
+}
+
+func TestRespawnWithoutCheckpoint(t *testing.T) {
+	input := &script{presses: []press{{ActionMoveDown, 0, 10}}}
+	w := newTestWorld(t, input, respawnLevel...)
+	run(w, 10)
+	w.Respawn()
+	if x, y := w.Player.Position.X, w.Player.Position.Y; x != float64(w.StartPos[0]) || y != float64(w.StartPos[1]) {
+		t.Errorf("respawned at %.1f, %.1f without a checkpoint, want the start %v", x, y, w.StartPos)
+	}
+}
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"fmt"
 	"image/color"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/joelschutz/stagehand"
 	input "github.com/quasilyte/ebitengine-input"
 	"github.com/sinisterstuf/project-scale/camera"
+	"github.com/sinisterstuf/project-scale/sim"
 )
 
 type State *Game
@@ -15,27 +22,48 @@ type State *Game
 type SceneIndex int
 
 const (
-	gameStart   = iota // Game start screen is shown
-	gameRunning        // The game is running the main game code
-	gamePaused         // The game is paused temporarily
-	gameOver           // The game has ended because you died
-	gameWon            // The game has ended because you won
+	gameStart            = iota // Game start screen is shown
+	gameRunning                 // The game is running the main game code
+	gamePaused                  // The game is paused temporarily
+	gameOver                    // The game has ended because you died
+	gameWon                     // The game has ended because you won
+	gameLevelSelect             // Choosing which unlocked level to play
+	gameOptions                 // Changing the settings
+	gameControls                // Rebinding the controls
+	gameMods                    // Turning mods on and off
+	gameCustomDifficulty        // Setting up the custom difficulty
 )
 
 type StageManager struct {
 	loadingScene *LoadingScene
+	errorScene   *ErrorScene // Shown instead of the game if assets failed to load
 	sceneManager *stagehand.SceneManager[State]
 	loaded       bool
+	options      Options
+	game         *Game
 }
 
 type Game struct {
 	Width, Height    int
 	Scenes           []stagehand.Scene[State]
 	ResetNeeded      bool
+	RespawnNeeded    bool
 	TextRenderer     *TextRenderer
 	BoldTextRenderer *TextRenderer
 	Stat             *Stat
-	StartPos         []int
+	Level            int             // Index of the level to play in the campaign
+	StartLevel       int             // Index of the level the start screen starts at
+	LevelName        string          // Identifier or number of the level to start at from the command line
+	Debug            string          // Names of the debug overlays to show
+	Levels           []string        // Identifiers of the levels in the campaign
+	World            *sim.World      // Simulation of the level being played
+	Record           string          // File to save a replay of each round to
+	Replay           *sim.Replay     // Replay to play back instead of reading the controls
+	Timer            *TimerServer    // Serves split timing to speedrun timers when it's turned on
+	GhostEnabled     bool            // Whether to race against the fastest round
+	Difficulty       *sim.Difficulty // Difficulty new rounds are played on
+	OptionsFrom      SceneIndex      // Scene to go back to from the options
+	MenuSound        *Sound
 	Fog              *Fog
 	Backdrops        Backdrops
 	Water            *Water
@@ -47,12 +75,18 @@ type Game struct {
 	Input            *input.Handler
 }
 
-func NewStageManager() *StageManager {
-	return &StageManager{loadingScene: NewLoadingScene(), loaded: false}
+func NewStageManager(options Options) *StageManager {
+	loadingScene := NewLoadingScene()
+	if options.SkipIntro {
+		loadingScene.MinTime = 0
+	}
+	return &StageManager{loadingScene: loadingScene, loaded: false, options: options}
 }
 
 func (s *StageManager) Layout(w, h int) (int, int) {
-	if s.loaded {
+	if s.errorScene != nil {
+		return s.errorScene.Layout(w, h)
+	} else if s.loaded {
 		return s.sceneManager.Layout(w, h)
 	} else {
 		return s.loadingScene.Layout(w, h)
@@ -60,18 +94,34 @@ func (s *StageManager) Layout(w, h int) (int, int) {
 }
 
 func (s *StageManager) Update() error {
-	if s.loaded {
+	if s.errorScene != nil {
+		return s.errorScene.Update()
+	} else if s.loaded {
+		mixer.Update()
 		return s.sceneManager.Update()
 	} else {
 		if s.loadingScene.IsLoaded() {
+			if loadReport.Failed() {
+				log.Println("Not starting the game,", loadReport)
+				s.errorScene = NewErrorScene(loadReport)
+				return nil
+			}
 			s.loaded = true
+			// A replay goes straight into its level, the controls only steer
+			// the menus while it's playing
+			if s.game.Replay != nil || s.options.SkipIntro {
+				s.game.ResetNeeded = true
+				s.sceneManager.SwitchTo(s.game.Scenes[gameRunning])
+			}
 		}
 		return s.loadingScene.Update()
 	}
 }
 
 func (s *StageManager) Draw(screen *ebiten.Image) {
-	if s.loaded {
+	if s.errorScene != nil {
+		s.errorScene.Draw(screen)
+	} else if s.loaded {
 		s.sceneManager.Draw(screen)
 	} else {
 		s.loadingScene.Draw(screen)
@@ -80,6 +130,7 @@ func (s *StageManager) Draw(screen *ebiten.Image) {
 }
 
 func loadGame(s *StageManager) {
+	preloadAssets(&s.loadingScene.LoadingState)
 
 	game := &Game{
 		Width:            gameWidth,
@@ -89,36 +140,54 @@ func loadGame(s *StageManager) {
 		Stat:             &Stat{},
 		Camera:           camera.NewCamera(gameWidth, gameHeight),
 		lastRender:       ebiten.NewImage(gameWidth, gameHeight),
+		Record:           s.options.Record,
+		LevelName:        s.options.Level,
+		Debug:            s.options.Debug,
+		GhostEnabled:     true,
+		Difficulty:       loadDifficulty(settings.Difficulty),
+	}
+
+	if s.options.Replay != "" {
+		if replay, err := loadReplay(s.options.Replay); err != nil {
+			loadReport.Add(s.options.Replay, err)
+		} else {
+			game.Replay = replay
+			game.Difficulty = &replay.Difficulty
+		}
+	}
+	game.Stat.Difficulty = game.Difficulty.Name
+
+	if s.options.Timer != "" {
+		if timer, err := NewTimerServer(s.options.Timer); err != nil {
+			loadReport.Add("-timer "+s.options.Timer, err)
+		} else {
+			game.Timer = timer
+		}
 	}
 
 	// Input setup
 	game.InputSystem.Init(input.SystemConfig{
 		DevicesEnabled: input.AnyDevice,
 	})
-	game.Keymap = input.Keymap{
-		ActionMoveUp:    {input.KeyUp, input.KeyW, input.KeyGamepadUp, input.KeyGamepadLStickUp},
-		ActionMoveLeft:  {input.KeyLeft, input.KeyA, input.KeyGamepadLeft, input.KeyGamepadLStickLeft},
-		ActionMoveDown:  {input.KeyDown, input.KeyS, input.KeyGamepadDown, input.KeyGamepadLStickDown},
-		ActionMoveRight: {input.KeyRight, input.KeyD, input.KeyGamepadRight, input.KeyGamepadLStickRight},
-		ActionPrimary:   {input.KeySpace, input.KeyGamepadA},
-		ActionMenu:      {input.KeyEscape, input.KeyGamepadStart},
-	}
+	game.Keymap = LoadKeymap()
 
 	game.Input = game.InputSystem.NewHandler(0, game.Keymap)
 
-	game.Stat.Load()
+	game.MenuSound = &Sound{Volume: 0.5}
+	game.MenuSound.AddSound("assets/sfx/menu-button", sampleRate, context)
 
 	game.Scenes = []stagehand.Scene[State]{
 		NewStartScene(game),
 		&GameScene{},
 		&PauseScreen{
 			Menu: &Menu{
-				Items:         []string{"Continue", "Back to main menu"},
+				Items:         []string{"Continue", "Options", "Back to main menu"},
 				X:             gameWidth / 2,
 				Y:             190,
 				color:         color.RGBA{255, 255, 255, 255},
 				selectedColor: color.RGBA{255, 255, 0, 255},
 				textRenderer:  game.TextRenderer,
+				sound:         game.MenuSound,
 				Input:         game.Input,
 			},
 		},
@@ -126,10 +195,11 @@ func loadGame(s *StageManager) {
 			Menu: &Menu{
 				Items:         []string{"Restart", "Back to main menu"},
 				X:             gameWidth / 2,
-				Y:             190,
+				Y:             178,
 				color:         color.RGBA{255, 255, 255, 255},
 				selectedColor: color.RGBA{255, 255, 0, 255},
 				textRenderer:  game.TextRenderer,
+				sound:         game.MenuSound,
 				Input:         game.Input,
 			},
 		},
@@ -137,16 +207,69 @@ func loadGame(s *StageManager) {
 			Menu: &Menu{
 				Items:         []string{"Restart", "Back to main menu"},
 				X:             gameWidth / 2,
-				Y:             190,
+				Y:             178,
+				color:         color.RGBA{255, 255, 255, 255},
+				selectedColor: color.RGBA{255, 255, 0, 255},
+				textRenderer:  game.TextRenderer,
+				sound:         game.MenuSound,
+				Input:         game.Input,
+			},
+		},
+		&LevelSelectScene{
+			Menu: &Menu{
+				X:             gameWidth / 2,
+				Y:             60,
 				color:         color.RGBA{255, 255, 255, 255},
 				selectedColor: color.RGBA{255, 255, 0, 255},
 				textRenderer:  game.TextRenderer,
+				sound:         game.MenuSound,
 				Input:         game.Input,
 			},
 		},
+		&OptionsScene{
+			Menu: &Menu{
+				X:             gameWidth / 2,
+				Y:             60,
+				color:         color.RGBA{255, 255, 255, 255},
+				selectedColor: color.RGBA{255, 255, 0, 255},
+				textRenderer:  game.TextRenderer,
+				sound:         game.MenuSound,
+				Input:         game.Input,
+			},
+		},
+		NewControlsScene(game),
+		NewModsScene(game),
+		NewCustomDifficultyScene(game),
 	}
 
 	s.sceneManager = stagehand.NewSceneManager[State](game.Scenes[gameStart], game)
 
+	s.game = game
+
 	NewGameScene(game, &s.loadingScene.LoadingState)
 }
+
+// loadReplay reads a recorded round from a file
+func loadReplay(name string) (*sim.Replay, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	replay := &sim.Replay{}
+	if err := replay.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// findLevel finds the index of a level in the campaign by its identifier or
+// its number, counting from 1
+func findLevel(levels []string, name string) (int, error) {
+	if i := slices.Index(levels, name); i >= 0 {
+		return i, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 1 && n <= len(levels) {
+		return n - 1, nil
+	}
+	return 0, fmt.Errorf("there's no level %s, the levels are %s", name, strings.Join(levels, ", "))
+}
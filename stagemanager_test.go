@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestFindLevel(t *testing.T) {
+	levels := []string{"Level_0", "Level_1", "7"}
+	for _, tc := range []struct {
+		name string
+		want int
+		ok   bool
+	}{
+		{"Level_0", 0, true},
+		{"Level_1", 1, true},
+		{"1", 0, true},
+		{"2", 1, true},
+		{"7", 2, true}, // The identifier wins over the number
+		{"3", 2, true},
+		{"0", 0, false},
+		{"4", 0, false},
+		{"-1", 0, false},
+		{"level_0", 0, false},
+		{"", 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := findLevel(levels, tc.name)
+			if (err == nil) != tc.ok || tc.ok && got != tc.want {
+				t.Errorf("findLevel(%q) = %d, %v, want %d and found %v", tc.name, got, err, tc.want, tc.ok)
+			}
+		})
+	}
+}
@@ -25,18 +25,33 @@ func (s *StartScene) Update() error {
 
 		if s.State.Input.ActionIsJustPressed(ActionPrimary) {
 			if s.Menu.Active == 0 {
+				s.State.Level = s.State.StartLevel
 				s.TransitionPhase = 1
 				s.Heartbeat.Pause()
 				s.Voice.Play()
 			} else if s.Menu.Active == 1 {
-				if ebiten.IsFullscreen() {
-					ebiten.SetFullscreen(false)
-					s.Menu.Items[1] = "Fullscreen: OFF"
-				} else {
-					ebiten.SetFullscreen(true)
-					s.Menu.Items[1] = "Fullscreen: ON"
-				}
+				s.Heartbeat.Pause()
+				s.SceneManager.SwitchTo(s.State.Scenes[gameLevelSelect])
+				return nil
 			} else if s.Menu.Active == 2 {
+				s.Heartbeat.Pause()
+				s.State.OptionsFrom = gameStart
+				s.SceneManager.SwitchTo(s.State.Scenes[gameOptions])
+				return nil
+			} else if s.Menu.Active == 3 {
+				s.State.GhostEnabled = !s.State.GhostEnabled
+				s.Menu.Items[3] = ghostMenuItem(s.State.GhostEnabled)
+			} else if s.Menu.Active == 4 {
+				settings.Difficulty = nextDifficulty(s.State.Difficulty.Name)
+				settings.Save()
+				s.State.Difficulty = loadDifficulty(settings.Difficulty)
+				s.State.Stat.Difficulty = s.State.Difficulty.Name
+				s.Menu.Items[4] = difficultyMenuItem(s.State.Difficulty.Name)
+			} else if s.Menu.Active == 5 {
+				s.Heartbeat.Pause()
+				s.SceneManager.SwitchTo(s.State.Scenes[gameMods])
+				return nil
+			} else if s.Menu.Active == 6 {
 				os.Exit(0)
 			}
 
@@ -78,7 +93,7 @@ func (s *StartScene) Draw(screen *ebiten.Image) {
 	}
 
 	fogOp := s.State.Fog.GetDrawImageOptions()
-	fogOp.GeoM.Translate(float64(-s.State.Fog.Image.Bounds().Dx()+s.State.StartPos[0])/2, -float64(s.State.Fog.Image.Bounds().Dy())+gameHeight)
+	fogOp.GeoM.Translate(float64(-s.State.Fog.Image.Bounds().Dx()+s.State.World.StartPos[0])/2, -float64(s.State.Fog.Image.Bounds().Dy())+gameHeight)
 	screen.DrawImage(s.State.Fog.Image, fogOp)
 
 }
@@ -89,8 +104,16 @@ func (s *StartScene) Load(st State, sm *stagehand.SceneManager[State]) {
 	s.BackgroundSprite.Update(0)
 }
 
+// ghostMenuItem is the label of the menu item that toggles the ghost racer
+func ghostMenuItem(enabled bool) string {
+	if enabled {
+		return "Ghost: ON"
+	}
+	return "Ghost: OFF"
+}
+
 func NewStartScene(game *Game) *StartScene {
-	voice := Sound{Volume: 0.5}
+	voice := Sound{Volume: 0.5, Bus: busVoice}
 	voice.AddSound("assets/voices/game-start", sampleRate, context)
 
 	heartbeat := Sound{Volume: 0.7}
@@ -102,12 +125,13 @@ func NewStartScene(game *Game) *StartScene {
 		Heartbeat:        heartbeat,
 		Voice:            voice,
 		Menu: &Menu{
-			Items:         []string{"Start game", "Fullscreen: OFF", "Quit"},
+			Items:         []string{"Start game", "Select level", "Options", ghostMenuItem(game.GhostEnabled), difficultyMenuItem(game.Difficulty.Name), "Mods", "Quit"},
 			X:             gameWidth / 2,
-			Y:             190,
+			Y:             160,
 			color:         color.RGBA{0, 0, 0, 255},
 			selectedColor: color.RGBA{255, 255, 0, 255},
 			textRenderer:  game.TextRenderer,
+			sound:         game.MenuSound,
 			Input:         game.Input,
 		},
 	}
@@ -2,51 +2,237 @@ package main
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/quasilyte/gdata"
+	"github.com/sinisterstuf/project-scale/sim"
 )
 
 // Stat stores the game statistics
 type Stat struct {
-	GameStart        time.Time
-	GameEnd          time.Time
 	LastHighestPoint int
-	HighestPoint     int
 	LastRound        int
-	FastestRound     int
+	LastTime         time.Duration         // Time of the last round won, to the millisecond
+	LastSplits       []time.Duration       // Split times of the last round, as far as it got
+	PreviousBest     []time.Duration       // Best split times before the last round, to compare it with
+	Level            string                // Identifier of the level being played
+	Difficulty       string                // Name of the difficulty being played on
+	Levels           map[string]*LevelStat // Records for each level and difficulty, see statKey
+	Order            []string              // Level identifiers in campaign order
+	Unlocked         int                   // How many levels of the campaign can be played
 }
 
-func (s *Stat) Load() {
-	s.HighestPoint = 0
-	m, err := gdata.Open(gdata.Config{
+// LevelStat stores the records of a single level
+type LevelStat struct {
+	HighestPoint int
+	FastestRound int             // Time of the fastest round in whole seconds
+	FastestTime  time.Duration   // Time of the fastest round to the millisecond
+	Ghost        sim.Trace       // Path taken in the fastest round
+	Splits       []time.Duration // Split times of the fastest round with the level's current splits
+	SplitLayout  string          // Where the split times were taken, see splitLayout
+}
+
+// Beat keeps the time, split times and path of a finished round if it's the
+// fastest yet, to the millisecond like they're saved. Split times taken at the
+// level's old splits are forgotten either way
+func (r *LevelStat) Beat(splits []sim.Split, times []time.Duration, ghost sim.Trace) bool {
+	r.CheckSplits(splits)
+	if len(times) == 0 {
+		return false
+	}
+	times = truncateSplits(times)
+	d := times[len(times)-1]
+	if r.FastestTime > 0 && (d > r.FastestTime || d == r.FastestTime && len(r.Ghost) > 0) {
+		return false
+	}
+	r.FastestTime, r.FastestRound = d, int(d.Seconds())
+	r.Splits = times
+	r.Ghost = append(sim.Trace(nil), ghost...)
+	return true
+}
+
+// CheckSplits forgets the best split times if they were taken at other heights
+// than the level's splits are at now, so they aren't compared with
+func (r *LevelStat) CheckSplits(splits []sim.Split) {
+	layout := splitLayout(splits)
+	if r.SplitLayout == "" && len(r.Splits) == len(splits) {
+		// Saved before the layout was, the best that can be done is the count
+		r.SplitLayout = layout
+	}
+	if r.SplitLayout != layout {
+		r.Splits, r.SplitLayout = nil, layout
+	}
+}
+
+// splitLayout writes the heights of a level's splits as comma-separated pixels
+func splitLayout(splits []sim.Split) string {
+	heights := make([]string, len(splits))
+	for i, split := range splits {
+		heights[i] = strconv.FormatFloat(split.Level, 'f', -1, 64)
+	}
+	return strings.Join(heights, ",")
+}
+
+// truncateSplits copies split times down to the millisecond
+func truncateSplits(splits []time.Duration) []time.Duration {
+	truncated := make([]time.Duration, len(splits))
+	for i, d := range splits {
+		truncated[i] = d.Truncate(time.Millisecond)
+	}
+	return truncated
+}
+
+// formatSplits writes split times as comma-separated milliseconds
+func formatSplits(splits []time.Duration) string {
+	ms := make([]string, len(splits))
+	for i, d := range splits {
+		ms[i] = strconv.FormatInt(d.Milliseconds(), 10)
+	}
+	return strings.Join(ms, ",")
+}
+
+// parseSplits reads split times saved by formatSplits
+func parseSplits(s string) []time.Duration {
+	var splits []time.Duration
+	for _, field := range strings.Split(s, ",") {
+		ms, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil
+		}
+		splits = append(splits, time.Duration(ms)*time.Millisecond)
+	}
+	return splits
+}
+
+// Current returns the records of the level being played
+func (s *Stat) Current() *LevelStat {
+	return s.ForLevel(s.Level)
+}
+
+// ForLevel returns the records of a level by its identifier on the difficulty
+// being played on
+func (s *Stat) ForLevel(level string) *LevelStat {
+	return s.record(statKey(level, s.Difficulty))
+}
+
+func (s *Stat) record(key string) *LevelStat {
+	if s.Levels == nil {
+		s.Levels = make(map[string]*LevelStat)
+	}
+	if s.Levels[key] == nil {
+		s.Levels[key] = &LevelStat{}
+	}
+	return s.Levels[key]
+}
+
+// statKey is what the records of a level on a difficulty are saved under,
+// records on normal difficulty are saved under the level alone like they were
+// before there were difficulties
+func statKey(level, difficulty string) string {
+	if difficulty == "" || difficulty == sim.DifficultyNormal.Name {
+		return level
+	}
+	return level + "." + difficulty
+}
+
+// Unlock makes the level at the given campaign index playable
+func (s *Stat) Unlock(index int) {
+	if index >= len(s.Order) {
+		index = len(s.Order) - 1
+	}
+	if index+1 > s.Unlocked {
+		s.Unlocked = index + 1
+	}
+}
+
+// openGameData opens where the game keeps what it saves between games
+func openGameData() (*gdata.Manager, error) {
+	return gdata.Open(gdata.Config{
 		AppName: "project_scale",
 	})
+}
+
+// Load reads the records of every level on every difficulty
+func (s *Stat) Load(levels []string) {
+	s.Order = levels
+	s.Levels = make(map[string]*LevelStat)
+	s.Unlocked = 1
+	m, err := openGameData()
 	if err != nil {
 		return
 	}
 
-	result, err := m.LoadItem("Stat.HighestPoint")
-	if err != nil {
-		return
+	if result, err := m.LoadItem("Stat.Unlocked"); err == nil {
+		s.Unlocked, _ = strconv.Atoi(string(result))
+	}
+	if s.Unlocked < 1 {
+		s.Unlocked = 1
+	}
+	if s.Unlocked > len(levels) {
+		s.Unlocked = len(levels)
 	}
-	s.HighestPoint, _ = strconv.Atoi(string(result))
 
-	result, err = m.LoadItem("Stat.FastestRound")
-	if err != nil {
-		return
+	for i, level := range levels {
+		for _, difficulty := range difficultyNames() {
+			key := statKey(level, difficulty)
+			record := s.record(key)
+			prefix := "Stat." + key
+
+			// Records from before there were several levels belong to the first
+			if i == 0 && key == level && !m.ItemExists(prefix+".HighestPoint") {
+				prefix = "Stat"
+			}
+
+			if result, err := m.LoadItem(prefix + ".HighestPoint"); err == nil {
+				record.HighestPoint, _ = strconv.Atoi(string(result))
+			}
+			if result, err := m.LoadItem(prefix + ".FastestRound"); err == nil {
+				record.FastestRound, _ = strconv.Atoi(string(result))
+			}
+			if result, err := m.LoadItem(prefix + ".Ghost"); err == nil {
+				record.Ghost.UnmarshalBinary(result)
+			}
+			if result, err := m.LoadItem(prefix + ".Splits"); err == nil {
+				record.Splits = parseSplits(string(result))
+			}
+			if result, err := m.LoadItem(prefix + ".SplitLayout"); err == nil {
+				record.SplitLayout = string(result)
+			}
+			if result, err := m.LoadItem(prefix + ".FastestTime"); err == nil {
+				ms, _ := strconv.ParseInt(string(result), 10, 64)
+				record.FastestTime = time.Duration(ms) * time.Millisecond
+			} else if n := len(record.Splits); n > 0 && int(record.Splits[n-1].Seconds()) == record.FastestRound {
+				// Saved before the fastest time was, the finish split is it
+				record.FastestTime = record.Splits[n-1]
+			} else {
+				record.FastestTime = time.Duration(record.FastestRound) * time.Second
+			}
+		}
 	}
-	s.FastestRound, _ = strconv.Atoi(string(result))
 }
 
 func (s *Stat) Save() {
-	m, err := gdata.Open(gdata.Config{
-		AppName: "project_scale",
-	})
+	m, err := openGameData()
 	if err != nil {
 		return
 	}
 
-	m.SaveItem("Stat.HighestPoint", []byte(strconv.Itoa(s.HighestPoint)))
-	m.SaveItem("Stat.FastestRound", []byte(strconv.Itoa(s.FastestRound)))
+	m.SaveItem("Stat.Unlocked", []byte(strconv.Itoa(s.Unlocked)))
+	for key, record := range s.Levels {
+		m.SaveItem("Stat."+key+".HighestPoint", []byte(strconv.Itoa(record.HighestPoint)))
+		m.SaveItem("Stat."+key+".FastestRound", []byte(strconv.Itoa(record.FastestRound)))
+		m.SaveItem("Stat."+key+".FastestTime", []byte(strconv.FormatInt(record.FastestTime.Milliseconds(), 10)))
+		if len(record.Ghost) > 0 {
+			ghost, _ := record.Ghost.MarshalBinary()
+			m.SaveItem("Stat."+key+".Ghost", ghost)
+		}
+		if len(record.Splits) > 0 {
+			m.SaveItem("Stat."+key+".Splits", []byte(formatSplits(record.Splits)))
+		} else {
+			// Forgotten because the level's splits changed
+			m.DeleteItem("Stat." + key + ".Splits")
+		}
+		m.SaveItem("Stat."+key+".SplitLayout", []byte(record.SplitLayout))
+	}
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sinisterstuf/project-scale/sim"
+)
+
+func TestBeat(t *testing.T) {
+	splits := []sim.Split{{Name: "Half way", Level: 160}, {Name: "Finish", Level: 16}}
+	moved := []sim.Split{{Name: "Half way", Level: 96}, {Name: "Finish", Level: 16}}
+	more := []sim.Split{{Name: "Low", Level: 240}, {Name: "High", Level: 96}, {Name: "Finish", Level: 16}}
+	s := time.Second
+	ghost := sim.Trace{{X: 1, Y: 2}}
+
+	for _, tc := range []struct {
+		name       string
+		record     LevelStat
+		splits     []sim.Split
+		times      []time.Duration
+		beat       bool
+		wantTime   time.Duration
+		wantSplits []time.Duration
+	}{
+		{
+			name:       "first round",
+			splits:     splits,
+			times:      []time.Duration{5 * s, 10 * s},
+			beat:       true,
+			wantTime:   10 * s,
+			wantSplits: []time.Duration{5 * s, 10 * s},
+		},
+		{
+			name:       "faster",
+			record:     LevelStat{FastestTime: 12 * s, Splits: []time.Duration{6 * s, 12 * s}, SplitLayout: "160,16"},
+			splits:     splits,
+			times:      []time.Duration{5 * s, 10 * s},
+			beat:       true,
+			wantTime:   10 * s,
+			wantSplits: []time.Duration{5 * s, 10 * s},
+		},
+		{
+			name:       "slower",
+			record:     LevelStat{FastestTime: 10 * s, Splits: []time.Duration{5 * s, 10 * s}, SplitLayout: "160,16"},
+			splits:     splits,
+			times:      []time.Duration{4 * s, 12 * s},
+			wantTime:   10 * s,
+			wantSplits: []time.Duration{5 * s, 10 * s},
+		},
+		{
+			name:       "truncated to the millisecond",
+			splits:     splits,
+			times:      []time.Duration{5*s + 1, 10*s + 1500*time.Microsecond},
+			beat:       true,
+			wantTime:   10*s + time.Millisecond,
+			wantSplits: []time.Duration{5 * s, 10*s + time.Millisecond},
+		},
+		{
+			name:     "slower after the splits moved",
+			record:   LevelStat{FastestTime: 10 * s, Splits: []time.Duration{5 * s, 10 * s}, SplitLayout: "160,16"},
+			splits:   moved,
+			times:    []time.Duration{7 * s, 12 * s},
+			wantTime: 10 * s,
+		},
+		{
+			name:     "slower after a split was added",
+			record:   LevelStat{FastestTime: 10 * s, Splits: []time.Duration{5 * s, 10 * s}, SplitLayout: "160,16"},
+			splits:   more,
+			times:    []time.Duration{3 * s, 7 * s, 12 * s},
+			wantTime: 10 * s,
+		},
+		{
+			name:       "faster after the splits moved",
+			record:     LevelStat{FastestTime: 10 * s, Splits: []time.Duration{5 * s, 10 * s}, SplitLayout: "160,16"},
+			splits:     moved,
+			times:      []time.Duration{7 * s, 9 * s},
+			beat:       true,
+			wantTime:   9 * s,
+			wantSplits: []time.Duration{7 * s, 9 * s},
+		},
+		{
+			name:       "saved before the layout was",
+			record:     LevelStat{FastestTime: 10 * s, Splits: []time.Duration{5 * s, 10 * s}},
+			splits:     splits,
+			times:      []time.Duration{4 * s, 12 * s},
+			wantTime:   10 * s,
+			wantSplits: []time.Duration{5 * s, 10 * s},
+		},
+		{
+			name:       "no times",
+			record:     LevelStat{FastestTime: 10 * s, Splits: []time.Duration{5 * s, 10 * s}, SplitLayout: "160,16"},
+			splits:     splits,
+			wantTime:   10 * s,
+			wantSplits: []time.Duration{5 * s, 10 * s},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			record := tc.record
+			if beat := record.Beat(tc.splits, tc.times, ghost); beat != tc.beat {
+				t.Errorf("beat the record: %v, want %v", beat, tc.beat)
+			}
+			if record.FastestTime != tc.wantTime {
+				t.Errorf("fastest time %v, want %v", record.FastestTime, tc.wantTime)
+			}
+			if tc.beat && record.FastestRound != int(tc.wantTime.Seconds()) {
+				t.Errorf("fastest round %d s, want %v in whole seconds", record.FastestRound, tc.wantTime)
+			}
+			if !slices.Equal(record.Splits, tc.wantSplits) {
+				t.Errorf("best splits %v, want %v", record.Splits, tc.wantSplits)
+			}
+			if record.SplitLayout != splitLayout(tc.splits) {
+				t.Errorf("split layout %q, want %q", record.SplitLayout, splitLayout(tc.splits))
+			}
+			if tc.beat && len(record.Ghost) != len(ghost) {
+				t.Errorf("ghost of %d points, want the round's %d", len(record.Ghost), len(ghost))
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/tinne26/etxt"
 )
 
@@ -18,7 +19,9 @@ type TextRenderer struct {
 func NewTextRenderer(fontName string) *TextRenderer {
 	font := loadFont(fontName)
 	r := etxt.NewStdRenderer()
-	r.SetFont(font)
+	if font != nil {
+		r.SetFont(font)
+	}
 	r.SetAlign(etxt.YCenter, etxt.XCenter)
 	return &TextRenderer{r, 0xff}
 }
@@ -26,6 +29,10 @@ func NewTextRenderer(fontName string) *TextRenderer {
 // xRatio is where to align horizontally: 0 = left, 100 = right
 // yRatio is where to align vertiocally: 0 = top, 100 = bottom
 func (r *TextRenderer) Draw(screen *ebiten.Image, text string, color color.Color, size int, xRatio int, yRatio int) {
+	if r.GetFont() == nil {
+		ebitenutil.DebugPrintAt(screen, text, screen.Bounds().Dx()*xRatio/100, screen.Bounds().Dy()*yRatio/100)
+		return
+	}
 	r.SetTarget(screen)
 	r.SetAlign(etxt.YCenter, etxt.XCenter)
 	r.SetColor(color)
@@ -34,6 +41,10 @@ func (r *TextRenderer) Draw(screen *ebiten.Image, text string, color color.Color
 }
 
 func (r *TextRenderer) DrawXY(screen *ebiten.Image, text string, color color.Color, size int, x int, y int, align etxt.HorzAlign) {
+	if r.GetFont() == nil {
+		ebitenutil.DebugPrintAt(screen, text, x, y)
+		return
+	}
 	r.SetTarget(screen)
 	r.SetAlign(etxt.Top, align)
 	r.SetColor(color)
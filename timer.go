@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sinisterstuf/project-scale/sim"
+	"github.com/tinne26/etxt"
+)
+
+// How long the time of a split stays on screen after reaching it
+const splitShowTicks = 180
+
+// Colours of split times faster and slower than the best ones
+var (
+	aheadColor  = color.RGBA{0, 255, 0, 255}
+	behindColor = color.RGBA{255, 80, 80, 255}
+)
+
+// formatTime writes a round's time like a speedrun timer, to the millisecond
+func formatTime(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+}
+
+// formatDelta writes how much slower a split was than the best one, or faster
+// if it's negative
+func formatDelta(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%s%d.%03d", sign, ms/1000, ms%1000)
+}
+
+// deltaColor is the colour a split's delta is written in
+func deltaColor(d time.Duration) color.Color {
+	if d < 0 {
+		return aheadColor
+	}
+	return behindColor
+}
+
+// DrawTimer draws the time of the round so far in the top right corner, and
+// the last split reached with how it compares to the best one for a while
+func (g *GameScene) DrawTimer(screen *ebiten.Image) {
+	const margin = 4
+	world := g.State.World
+	x := g.State.Width - margin
+	g.State.TextRenderer.DrawXY(screen, formatTime(world.Elapsed()), color.White, 8, x, margin, etxt.Right)
+
+	if g.SplitTicks <= 0 || g.SplitShown >= len(world.SplitTimes) {
+		return
+	}
+	g.State.TextRenderer.DrawXY(screen, world.Splits[g.SplitShown].Name, color.White, 8, x, margin+10, etxt.Right)
+	best := g.State.Stat.PreviousBest
+	if delta, ok := sim.SplitDelta(world.SplitTimes, best, g.SplitShown); ok && len(best) == len(world.Splits) {
+		g.State.TextRenderer.DrawXY(screen, formatDelta(delta), deltaColor(delta), 8, x, margin+20, etxt.Right)
+	}
+}
+
+// splitsSummary lists the times of the splits reached in the last round, and
+// how they compare to the best ones from before it
+func splitsSummary(splits []sim.Split, times, best []time.Duration) string {
+	var b strings.Builder
+	for i, t := range times {
+		if i >= len(splits) {
+			break
+		}
+		fmt.Fprintf(&b, "%s  %s", splits[i].Name, formatTime(t))
+		if delta, ok := sim.SplitDelta(times, best, i); ok && len(best) == len(splits) {
+			fmt.Fprintf(&b, "  %s", formatDelta(delta))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
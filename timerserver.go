@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// How long a timer gets to read a command before it's disconnected, so that
+// a stuck one can't hold up the game
+const timerWriteTimeout = 100 * time.Millisecond
+
+// TimerServer tells speedrun timers connected over TCP when a round starts,
+// reaches a split and finishes, with one LiveSplit Server command per line
+type TimerServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	clients  []net.Conn
+	gameTime atomic.Int64 // In-game time of the round so far, as a time.Duration
+}
+
+// NewTimerServer starts listening for timers on the given address
+func NewTimerServer(address string) (*TimerServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	s := &TimerServer{listener: listener}
+	log.Println("Serving split timing on", listener.Addr())
+	go s.accept()
+	return s, nil
+}
+
+func (s *TimerServer) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			log.Println("Stopped serving split timing:", err)
+			return
+		}
+		log.Println("Timer connected from", conn.RemoteAddr())
+		s.mu.Lock()
+		s.clients = append(s.clients, conn)
+		s.mu.Unlock()
+		go s.serve(conn)
+	}
+}
+
+// serve answers a timer asking for the in-game time until it disconnects,
+// anything else it sends is ignored
+func (s *TimerServer) serve(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "getcurrentgametime" {
+			s.mu.Lock()
+			s.write(conn, formatTime(time.Duration(s.gameTime.Load())))
+			s.mu.Unlock()
+		}
+	}
+	s.mu.Lock()
+	s.drop(conn)
+	s.mu.Unlock()
+}
+
+// send sends commands to every connected timer, dropping the ones that can't
+// keep up
+func (s *TimerServer) send(commands ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range slices.Clone(s.clients) {
+		if err := s.write(conn, commands...); err != nil {
+			log.Println("Timer disconnected:", err)
+			s.drop(conn)
+		}
+	}
+}
+
+func (s *TimerServer) write(conn net.Conn, lines ...string) error {
+	conn.SetWriteDeadline(time.Now().Add(timerWriteTimeout))
+	_, err := io.WriteString(conn, strings.Join(lines, "\r\n")+"\r\n")
+	return err
+}
+
+func (s *TimerServer) drop(conn net.Conn) {
+	conn.Close()
+	for i, c := range s.clients {
+		if c == conn {
+			s.clients = append(s.clients[:i], s.clients[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetTime keeps track of the in-game time for timers that ask for it
+func (s *TimerServer) SetTime(d time.Duration) {
+	s.gameTime.Store(int64(d))
+}
+
+// Start starts a new run on the timers, timing the game's time instead of
+// their own
+func (s *TimerServer) Start() {
+	s.SetTime(0)
+	s.send("reset", "starttimer", "initgametime", "setgametime "+formatTime(0))
+}
+
+// Split tells the timers a split was reached at the given in-game time, the
+// finish is the last split and ends the run
+func (s *TimerServer) Split(d time.Duration) {
+	s.SetTime(d)
+	s.send("setgametime "+formatTime(d), "split")
+}
+
+// Pause stops the timers' game time while the game isn't being played
+func (s *TimerServer) Pause(d time.Duration) {
+	s.SetTime(d)
+	s.send("setgametime "+formatTime(d), "pausegametime")
+}
+
+// Resume starts the timers' game time again
+func (s *TimerServer) Resume() {
+	s.send("unpausegametime")
+}
@@ -4,15 +4,17 @@ if [[ $1 == "-h" ]]; then
 	echo "this script extracts frame tags from an aseprite-exported sprite JSON and generates Go constants to refer to them by name" >&2
 fi
 
-if [[ $# -ne 3 ]]; then
-	echo "this is script needs exactly 3 arguments: input file and output file and const prefix" >&2
+if [[ $# -lt 3 || $# -gt 4 ]]; then
+	echo "this is script needs 3 arguments: input file and output file and const prefix, and optionally the package name" >&2
 	exit 1
 fi
 
+package="${4:-main}"
+
 echo "Generating $3AnimationTags into $2 from sprite $1"
 
 truncate -s 0 "$2"
-echo -e "package main\n" >> "$2"
+echo -e "package $package\n" >> "$2"
 echo -e "// DO NOT EDIT\n// Generated by: $0\n" >> "$2"
 
 echo -e "type $3AnimationTags uint8\n\nconst (" >> "$2"
@@ -2,42 +2,20 @@ package main
 
 import (
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/sinisterstuf/project-scale/camera"
+	"github.com/sinisterstuf/project-scale/sim"
 )
 
-const WaterSpeed = 0.35
-
+// Water draws the simulation's rising water
 type Water struct {
-	Level      float64
-	StartLevel float64
-	Image      *ebiten.Image
-	Paused     bool
+	*sim.Water
+	Image *ebiten.Image
 }
 
-func NewWater(startLevel float64) *Water {
+func NewWater(water *sim.Water) *Water {
 	return &Water{
-		Level:      startLevel,
-		StartLevel: startLevel,
-		Image:      loadImage("assets/backdrop/Project-scale-parallax-backdrop_0000_Water-1.png"),
-	}
-}
-
-func (w *Water) Update(increaseWaterLevel bool) {
-	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
-		w.Paused = !w.Paused
-	}
-
-	if !CheatsAllowed || !w.Paused {
-		increase := 1.0
-		if !increaseWaterLevel {
-			increase = -8.0
-		}
-		w.Level -= increase * WaterSpeed
-
-		if w.Level > w.StartLevel {
-			w.Level = w.StartLevel
-		}
+		Water: water,
+		Image: loadImage("assets/backdrop/Project-scale-parallax-backdrop_0000_Water-1.png"),
 	}
 }
 
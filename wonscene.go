@@ -5,21 +5,32 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/joelschutz/stagehand"
+	"github.com/tinne26/etxt"
 )
 
 // WonScreen is shown when the game is won
 type WonScene struct {
 	BaseScene
-	Menu *Menu
+	Menu      *Menu
+	NextLevel bool // whether there's another level after the one just won
 }
 
 func (s *WonScene) Update() error {
 	s.Menu.Update()
 	if s.State.Input.ActionIsJustPressed(ActionPrimary) {
-		if s.Menu.Active == 0 {
+		active := s.Menu.Active
+		if !s.NextLevel {
+			active++ // there's no "Next level" item to skip over
+		}
+		if active == 0 {
+			s.State.Level++
+			s.State.ResetNeeded = true
+			s.SceneManager.SwitchTo(s.State.Scenes[gameRunning])
+		} else if active == 1 {
 			s.State.ResetNeeded = true
 			s.SceneManager.SwitchTo(s.State.Scenes[gameRunning])
-		} else if s.Menu.Active == 1 {
+		} else if active == 2 {
 			s.SceneManager.SwitchTo(s.State.Scenes[gameStart])
 		}
 	}
@@ -31,11 +42,26 @@ func (s *WonScene) Update() error {
 func (s *WonScene) Draw(screen *ebiten.Image) {
 	screen.DrawImage(s.State.lastRender, &ebiten.DrawImageOptions{})
 
+	record := s.State.Stat.Current()
 	s.State.TextRenderer.Draw(screen, "CONGRATS!", color.White, 8, 50, 10)
 	s.State.TextRenderer.Draw(screen, fmt.Sprintf(
-		"Your last round: %d min %d sec\nYour fastest round: %d min %d sec",
-		int(s.State.Stat.LastRound/60), int(s.State.Stat.LastRound)%60, int(s.State.Stat.FastestRound/60), int(s.State.Stat.FastestRound)%60,
-	), color.White, 8, 50, 40)
+		"Your last round: %s\nYour fastest round: %s",
+		formatTime(s.State.Stat.LastTime), formatTime(record.FastestTime),
+	), color.White, 8, 50, 30)
+	s.State.TextRenderer.DrawXY(screen, splitsSummary(
+		s.State.World.Splits, s.State.Stat.LastSplits, s.State.Stat.PreviousBest,
+	), color.White, 8, gameWidth/2, 100, etxt.XCenter)
 
 	s.Menu.Draw(screen)
 }
+
+func (s *WonScene) Load(st State, sm *stagehand.SceneManager[State]) {
+	s.BaseScene.Load(st, sm)
+	s.NextLevel = s.State.Level+1 < len(s.State.Levels)
+	s.Menu.Active = 0
+	if s.NextLevel {
+		s.Menu.Items = []string{"Next level", "Restart", "Back to main menu"}
+	} else {
+		s.Menu.Items = []string{"Restart", "Back to main menu"}
+	}
+}